@@ -1,14 +1,32 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/gclamigueiro/dragon-ball-api/internal/admin"
+	"github.com/gclamigueiro/dragon-ball-api/internal/cache"
 	"github.com/gclamigueiro/dragon-ball-api/internal/character"
 	"github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
 	"github.com/gclamigueiro/dragon-ball-api/internal/config"
 	"github.com/gclamigueiro/dragon-ball-api/internal/db"
+	"github.com/gclamigueiro/dragon-ball-api/internal/health"
+	"github.com/gclamigueiro/dragon-ball-api/internal/logging"
+	"github.com/gclamigueiro/dragon-ball-api/internal/metrics"
+	"github.com/gclamigueiro/dragon-ball-api/internal/middleware"
+	"github.com/gclamigueiro/dragon-ball-api/internal/planet"
+	"github.com/gclamigueiro/dragon-ball-api/internal/refresh"
+	"github.com/gclamigueiro/dragon-ball-api/internal/server"
+	"github.com/gclamigueiro/dragon-ball-api/internal/tracing"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"gorm.io/gorm"
 )
 
 func main() { // Initialize the application
@@ -20,28 +38,230 @@ func main() { // Initialize the application
 	// Load application config from environment
 	cfg := config.LoadConfig()
 
+	// Every package logs with the default logger unless another one is injected
+	logger := logging.New(os.Stdout, cfg.LogFormat, cfg.LogLevel)
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TracingExporter, cfg.ServiceName)
+	if err != nil {
+		fatal(logger, "failed to set up the traces", err)
+	}
+
 	db := db.Connect(db.Config{
 		Host:     cfg.DBHost,
 		Port:     cfg.DBPort,
 		User:     cfg.DBUser,
 		Password: cfg.DBPassword,
 		Name:     cfg.DBName,
+
+		Logger:             logger,
+		SlowQueryThreshold: cfg.SlowQueryThreshold,
 	})
 
-	dgClient := dragonball.NewClient(cfg.DBAPIBaseURL)
+	// Prometheus metrics of the requests, the api, the database and the caches
+	appMetrics := metrics.New()
+	if err := appMetrics.InstrumentDB(db, cfg.DBName); err != nil {
+		fatal(logger, "failed to instrument the database", err)
+	}
+	if err := tracing.InstrumentDB(db); err != nil {
+		fatal(logger, "failed to trace the database", err)
+	}
+
+	dgClient := appMetrics.InstrumentClient(dragonball.NewClient(cfg.DBAPIBaseURL,
+		dragonball.WithTimeout(cfg.UpstreamTimeout),
+		dragonball.WithTransport(tracing.NewTransport(http.DefaultTransport)),
+		dragonball.WithLogger(logger),
+		dragonball.WithRetry(cfg.UpstreamMaxRetries, cfg.UpstreamRetryBaseDelay, cfg.UpstreamRetryMaxDelay),
+		dragonball.WithCircuitBreaker(cfg.UpstreamBreakerThreshold, cfg.UpstreamBreakerCooldown),
+	))
 
 	// Set up repository, service, and handler
-	repo := character.NewStorage(db)
-	service := character.NewService(dgClient, repo)
+	repo := character.NewStorage(db, character.WithStorageLogger(logger.With("repository", "characters")))
+	if cfg.RepositoryCacheSize > 0 {
+		cachedRepo := character.NewCachedRepository(repo, cfg.RepositoryCacheSize, cfg.RepositoryCacheTTL)
+		if err := appMetrics.RegisterRepositoryCache(cachedRepo); err != nil {
+			fatal(logger, "failed to register the repository cache metrics", err)
+		}
+		repo = cachedRepo
+	}
+
+	serviceMetrics := &character.Metrics{}
+	if err := appMetrics.RegisterCharacterService(serviceMetrics); err != nil {
+		fatal(logger, "failed to register the character metrics", err)
+	}
+	serviceOpts := []character.Option{
+		character.WithCacheTTL(cfg.CacheTTL),
+		character.WithMetrics(serviceMetrics),
+		character.WithLogger(logger),
+	}
+
+	sharedCache := newCache(cfg)
+	if sharedCache != nil {
+		serviceOpts = append(serviceOpts, character.WithCache(sharedCache))
+	}
+
+	if cfg.NegativeCacheTTL > 0 {
+		var misses character.MissStore
+		switch {
+		case cfg.NegativeCachePersist:
+			misses = character.NewStorageMissStore(db, cfg.NegativeCacheTTL)
+		case sharedCache != nil:
+			misses = character.NewCacheMissStore(sharedCache, cfg.NegativeCacheTTL)
+		default:
+			misses = character.NewMemoryMissStore(cfg.NegativeCacheTTL, character.DefaultMaxMisses)
+		}
+		serviceOpts = append(serviceOpts, character.WithMissStore(misses))
+	}
+	service := character.NewService(dgClient, repo, serviceOpts...)
 	handler := character.NewHandler(service)
 
+	planetOpts := []planet.Option{
+		planet.WithCacheTTL(cfg.CacheTTL),
+		planet.WithLogger(logger),
+	}
+	if sharedCache != nil {
+		planetOpts = append(planetOpts, planet.WithCache(sharedCache))
+	}
+	planetRepo := planet.NewStorage(db, planet.WithStorageLogger(logger.With("repository", "planets")))
+	planetService := planet.NewService(dgClient, planetRepo, repo, planetOpts...)
+	planetHandler := planet.NewHandler(planetService)
+
+	// Check the saved characters against the api in the background
+	var refreshWorker *refresh.Worker
+	if cfg.RefreshInterval > 0 {
+		workerOpts := []refresh.Option{
+			refresh.WithConcurrency(cfg.RefreshConcurrency),
+			refresh.WithRate(cfg.RefreshRate),
+			refresh.WithBatchSize(cfg.RefreshBatchSize),
+			refresh.WithLogger(logger),
+		}
+		if sharedCache != nil {
+			workerOpts = append(workerOpts, refresh.WithCache(sharedCache))
+		}
+		refreshWorker = refresh.NewWorker(dgClient, repo, cfg.RefreshInterval, workerOpts...)
+		refreshWorker.Start()
+	}
+
 	// Set up Gin router and register routes
-	r := gin.Default()
+	r := gin.New()
+	r.Use(
+		gin.Recovery(),
+		tracing.Middleware(cfg.ServiceName),
+		middleware.RequestID(),
+		middleware.AccessLog(logger),
+		appMetrics.Middleware(),
+	)
+	appMetrics.RegisterRoutes(r)
 	handler.RegisterRoutes(r)
+	planetHandler.RegisterRoutes(r)
+	newHealthHandler(cfg, logger, db, dgClient, sharedCache).RegisterRoutes(r)
+
+	if cfg.AdminToken != "" {
+		adminGroup := admin.Group(r, cfg.AdminToken)
+		handler.RegisterAdminRoutes(adminGroup)
+		if refreshWorker != nil {
+			refresh.NewHandler(refreshWorker).RegisterAdminRoutes(adminGroup)
+		}
+	} else {
+		logger.Warn("ADMIN_TOKEN is not set, the admin routes are disabled")
+	}
 
-	log.Printf("Server listening on port %s", cfg.APIPort)
-	if err := r.Run(":" + cfg.APIPort); err != nil {
-		log.Fatalf("failed to run server: %v", err)
+	// Once the requests are drained, stop the workers before closing
+	// what they use: the shared cache and the database pool. The pending
+	// spans are flushed last.
+	var shutdown []func() error
+	if refreshWorker != nil {
+		shutdown = append(shutdown, func() error {
+			refreshWorker.Stop()
+			return nil
+		})
 	}
+	if sharedCache != nil {
+		shutdown = append(shutdown, sharedCache.Close)
+	}
+	shutdown = append(shutdown, func() error { return closeDB(db) })
+	shutdown = append(shutdown, func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+		defer cancel()
+		return shutdownTracing(ctx)
+	})
+
+	srv := server.New(r, server.Config{
+		Addr:              ":" + cfg.APIPort,
+		ReadTimeout:       cfg.HTTPReadTimeout,
+		ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+		WriteTimeout:      cfg.HTTPWriteTimeout,
+		IdleTimeout:       cfg.HTTPIdleTimeout,
+		MaxHeaderBytes:    cfg.HTTPMaxHeaderBytes,
+	})
+
+	// SIGTERM is what docker and kubernetes send to stop the container
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger.Info("server listening", "port", cfg.APIPort)
+	if err := server.Run(ctx, srv, cfg.ShutdownTimeout, shutdown...); err != nil {
+		fatal(logger, "server stopped with errors", err)
+	}
+	logger.Info("server stopped")
+}
+
+// newHealthHandler checks the database for the readiness probe, and the
+// shared cache and the api if enabled. The api can answer without them.
+func newHealthHandler(cfg *config.Config, logger *slog.Logger, db *gorm.DB, dgClient dragonball.Client, sharedCache cache.Cache) *health.Handler {
+	opts := []health.Option{
+		health.WithTimeout(cfg.HealthCheckTimeout),
+		health.WithLogger(logger),
+		health.WithCheck("database", func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		}),
+	}
+
+	if pinger, ok := sharedCache.(interface{ Ping(context.Context) error }); ok {
+		opts = append(opts, health.WithOptionalCheck("cache", pinger.Ping, 0))
+	}
+
+	if cfg.HealthUpstreamTTL > 0 {
+		opts = append(opts, health.WithOptionalCheck("upstream", func(ctx context.Context) error {
+			_, err := dgClient.ListPlanets(ctx, 1, 1)
+			return err
+		}, cfg.HealthUpstreamTTL))
+	}
+
+	return health.NewHandler(opts...)
+}
+
+// closeDB closes the connection pool of the database
+func closeDB(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get the database pool: %w", err)
+	}
+	return sqlDB.Close()
+}
+
+// newCache creates the cache shared by the replicas, or nil if it is disabled
+func newCache(cfg *config.Config) cache.Cache {
+	switch cfg.CacheBackend {
+	case "memory":
+		return cache.NewMemory(cache.DefaultMemoryMaxEntries)
+	case "redis":
+		return cache.NewRedis(cache.RedisOptions{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		})
+	default:
+		return nil
+	}
+}
 
+// fatal logs the error and exits
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/cache"
+	"github.com/gclamigueiro/dragon-ball-api/internal/catalog"
+	"github.com/gclamigueiro/dragon-ball-api/internal/character"
+	"github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
+	"github.com/gclamigueiro/dragon-ball-api/internal/config"
+	"github.com/gclamigueiro/dragon-ball-api/internal/db"
+	"github.com/gclamigueiro/dragon-ball-api/internal/logging"
+	"github.com/gclamigueiro/dragon-ball-api/internal/planet"
+	"github.com/joho/godotenv"
+)
+
+// Syncs the whole catalog of the api into the database. An interrupted sync,
+// e.g. with Ctrl+C, continues from the last page saved on the next run.
+func main() {
+	restart := flag.Bool("restart", false, "start from the first page even if the last sync was interrupted")
+	pageSize := flag.Int("page-size", catalog.DefaultPageSize, "rows asked to the api per page")
+	flag.Parse()
+
+	if err := godotenv.Load(".env.local"); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	// Load application config from environment
+	cfg := config.LoadConfig()
+
+	// The logs go to stderr, stdout only has the report
+	logger := logging.New(os.Stderr, cfg.LogFormat, cfg.LogLevel)
+	slog.SetDefault(logger)
+
+	db := db.Connect(db.Config{
+		Host:     cfg.DBHost,
+		Port:     cfg.DBPort,
+		User:     cfg.DBUser,
+		Password: cfg.DBPassword,
+		Name:     cfg.DBName,
+
+		Logger:             logger,
+		SlowQueryThreshold: cfg.SlowQueryThreshold,
+	})
+
+	dgClient := dragonball.NewClient(cfg.DBAPIBaseURL,
+		dragonball.WithTimeout(cfg.UpstreamTimeout),
+		dragonball.WithRetry(cfg.UpstreamMaxRetries, cfg.UpstreamRetryBaseDelay, cfg.UpstreamRetryMaxDelay),
+		dragonball.WithCircuitBreaker(cfg.UpstreamBreakerThreshold, cfg.UpstreamBreakerCooldown),
+		dragonball.WithLogger(logger),
+	)
+
+	syncOpts := []catalog.Option{
+		catalog.WithPageSize(*pageSize),
+		catalog.WithLogger(logger),
+	}
+
+	// Only Redis is shared with the api, the memory cache lives in its process
+	if cfg.CacheBackend == "redis" {
+		sharedCache := cache.NewRedis(cache.RedisOptions{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		})
+		defer sharedCache.Close()
+		syncOpts = append(syncOpts, catalog.WithCache(sharedCache))
+	}
+
+	service := catalog.NewService(dgClient,
+		character.NewStorage(db, character.WithStorageLogger(logger.With("repository", "characters"))),
+		planet.NewStorage(db, planet.WithStorageLogger(logger.With("repository", "planets"))),
+		catalog.NewCheckpointStorage(db),
+		syncOpts...,
+	)
+
+	// Stop between requests on Ctrl+C, the pages already synced are kept
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	report, err := service.Sync(ctx, catalog.SyncOptions{Restart: *restart})
+	if err != nil {
+		logger.Error("catalog sync failed, run it again to resume", "error", err)
+		os.Exit(1)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		logger.Error("failed to write the report", "error", err)
+		os.Exit(1)
+	}
+}
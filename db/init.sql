@@ -2,5 +2,54 @@ CREATE TABLE IF NOT EXISTS characters (
     id INTEGER PRIMARY KEY,
     name VARCHAR NOT NULL, --  It a reserved word, but GORM handles it fine
     ki VARCHAR,
-    race VARCHAR
-);
\ No newline at end of file
+    ki_numeric NUMERIC,
+    max_ki VARCHAR,
+    max_ki_numeric NUMERIC,
+    race VARCHAR,
+    gender VARCHAR,
+    description TEXT,
+    image VARCHAR,
+    affiliation VARCHAR,
+    deleted_at TIMESTAMPTZ,
+    transformations_fetched BOOLEAN NOT NULL DEFAULT FALSE,
+    origin_planet_id INTEGER, -- No foreign key, the planet may not be cached yet
+    fetched_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_characters_origin_planet_id ON characters(origin_planet_id);
+
+CREATE TABLE IF NOT EXISTS transformations (
+    id INTEGER PRIMARY KEY,
+    character_id INTEGER NOT NULL REFERENCES characters(id),
+    name VARCHAR NOT NULL,
+    ki VARCHAR,
+    ki_numeric NUMERIC,
+    image VARCHAR,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_transformations_character_id ON transformations(character_id);
+
+CREATE TABLE IF NOT EXISTS planets (
+    id INTEGER PRIMARY KEY,
+    name VARCHAR NOT NULL,
+    is_destroyed BOOLEAN NOT NULL DEFAULT FALSE,
+    description TEXT,
+    image VARCHAR,
+    deleted_at TIMESTAMPTZ,
+    characters_fetched BOOLEAN NOT NULL DEFAULT FALSE
+);
+CREATE TABLE IF NOT EXISTS character_misses (
+    name VARCHAR PRIMARY KEY,
+    missed_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS sync_checkpoints (
+    name VARCHAR PRIMARY KEY,
+    next_page INTEGER NOT NULL,
+    report JSONB,
+    started_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
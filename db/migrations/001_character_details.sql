@@ -0,0 +1,8 @@
+-- Adds the remaining fields of the upstream character model.
+-- Only needed for databases created before they were part of init.sql.
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS max_ki VARCHAR;
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS gender VARCHAR;
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS description TEXT;
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS image VARCHAR;
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS affiliation VARCHAR;
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
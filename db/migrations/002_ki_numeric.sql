@@ -0,0 +1,4 @@
+-- Numeric power levels parsed from the raw ki strings.
+-- Rows cached before this migration keep NULL until they are fetched again.
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS ki_numeric NUMERIC;
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS max_ki_numeric NUMERIC;
@@ -0,0 +1,14 @@
+-- Transformations of each character, fetched from the upstream detail endpoint.
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS transformations_fetched BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS transformations (
+    id INTEGER PRIMARY KEY,
+    character_id INTEGER NOT NULL REFERENCES characters(id),
+    name VARCHAR NOT NULL,
+    ki VARCHAR,
+    ki_numeric NUMERIC,
+    image VARCHAR,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_transformations_character_id ON transformations(character_id);
@@ -0,0 +1,15 @@
+-- Planets and the origin planet of each character.
+CREATE TABLE IF NOT EXISTS planets (
+    id INTEGER PRIMARY KEY,
+    name VARCHAR NOT NULL,
+    is_destroyed BOOLEAN NOT NULL DEFAULT FALSE,
+    description TEXT,
+    image VARCHAR,
+    deleted_at TIMESTAMPTZ,
+    characters_fetched BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+-- No foreign key, the planet may not be cached yet
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS origin_planet_id INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_characters_origin_planet_id ON characters(origin_planet_id);
@@ -0,0 +1,4 @@
+-- When each character was last fetched from upstream and last changed.
+-- Rows without fetched_at are treated as stale and refreshed on the next lookup.
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS fetched_at TIMESTAMPTZ;
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ;
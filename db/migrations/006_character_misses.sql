@@ -0,0 +1,6 @@
+-- Names the upstream API does not know (negative cache), keyed by the
+-- lowercased name. Rows older than NEGATIVE_CACHE_TTL are ignored.
+CREATE TABLE IF NOT EXISTS character_misses (
+    name VARCHAR PRIMARY KEY,
+    missed_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,10 @@
+-- Progress of the catalog sync, so an interrupted sync resumes from the
+-- next page of characters instead of starting over.
+CREATE TABLE IF NOT EXISTS sync_checkpoints (
+    name VARCHAR PRIMARY KEY,
+    next_page INTEGER NOT NULL,
+    report JSONB,
+    started_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
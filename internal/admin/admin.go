@@ -0,0 +1,31 @@
+// Package admin protects the maintenance routes of the api
+package admin
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/problem"
+)
+
+// Group creates the /admin route group, every route in it requires the token
+func Group(r gin.IRouter, token string) *gin.RouterGroup {
+	return r.Group("/admin", RequireToken(token))
+}
+
+// RequireToken rejects the requests without "Authorization: Bearer <token>".
+// An empty token rejects every request.
+func RequireToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		given, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="admin"`)
+			problem.Write(c, problem.New(http.StatusUnauthorized, "unauthorized", "Unauthorized", "a valid admin token is required"))
+			return
+		}
+		c.Next()
+	}
+}
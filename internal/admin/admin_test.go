@@ -0,0 +1,36 @@
+package admin_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/admin"
+)
+
+func serve(token, header string) int {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	admin.Group(r, token).GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/admin/ping", nil)
+	if header != "" {
+		req.Header.Set("Authorization", header)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRequireToken(t *testing.T) {
+	assert.Equal(t, http.StatusNoContent, serve("s3cret", "Bearer s3cret"))
+	assert.Equal(t, http.StatusUnauthorized, serve("s3cret", "Bearer wrong"))
+	assert.Equal(t, http.StatusUnauthorized, serve("s3cret", "s3cret"))
+	assert.Equal(t, http.StatusUnauthorized, serve("s3cret", ""))
+	assert.Equal(t, http.StatusUnauthorized, serve("", "Bearer "))
+}
@@ -0,0 +1,18 @@
+// Package cache provides the key-value caches shared by the domains
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache stores values by key for a while. A zero ttl never expires.
+type Cache interface {
+	// Get returns false if the key is missing or expired
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	// DeletePrefix removes every key starting with the prefix, returning how many were removed
+	DeletePrefix(ctx context.Context, prefix string) (int64, error)
+	Close() error
+}
@@ -0,0 +1,201 @@
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/cache"
+	"github.com/gclamigueiro/dragon-ball-api/internal/cache/resptest"
+)
+
+// backends returns every implementation, the Redis one backed by an in-process server
+func backends(t *testing.T) map[string]cache.Cache {
+	server, err := resptest.NewServer()
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+
+	redis := cache.NewRedis(cache.RedisOptions{Addr: server.Addr()})
+	t.Cleanup(func() { _ = redis.Close() })
+
+	return map[string]cache.Cache{
+		"memory": cache.NewMemory(100),
+		"redis":  redis,
+	}
+}
+
+func TestCache_SetGetDelete(t *testing.T) {
+	for name, c := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			_, ok, err := c.Get(ctx, "character:goku")
+			assert.NoError(t, err)
+			assert.False(t, ok)
+
+			// Values are binary safe
+			value := []byte("{\"name\":\"Goku\"}\r\n\x00")
+			assert.NoError(t, c.Set(ctx, "character:goku", value, 0))
+
+			got, ok, err := c.Get(ctx, "character:goku")
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, value, got)
+
+			assert.NoError(t, c.Delete(ctx, "character:goku"))
+			_, ok, _ = c.Get(ctx, "character:goku")
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestCache_Expires(t *testing.T) {
+	for name, c := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, c.Set(ctx, "character:goku", []byte("1"), 20*time.Millisecond))
+			_, ok, _ := c.Get(ctx, "character:goku")
+			assert.True(t, ok)
+
+			time.Sleep(40 * time.Millisecond)
+			_, ok, _ = c.Get(ctx, "character:goku")
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestCache_SubMillisecondTTL(t *testing.T) {
+	for name, c := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, c.Set(ctx, "character:goku", []byte("1"), 500*time.Microsecond))
+
+			time.Sleep(20 * time.Millisecond)
+			_, ok, _ := c.Get(ctx, "character:goku")
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestCache_DeletePrefix(t *testing.T) {
+	for name, c := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, c.Set(ctx, "miss:gokku", []byte("1"), 0))
+			assert.NoError(t, c.Set(ctx, "miss:vegetta", []byte("1"), 0))
+			assert.NoError(t, c.Set(ctx, "miss*:other", []byte("1"), 0))
+			assert.NoError(t, c.Set(ctx, "character:goku", []byte("1"), 0))
+
+			removed, err := c.DeletePrefix(ctx, "miss:")
+			assert.NoError(t, err)
+			assert.Equal(t, int64(2), removed)
+
+			_, ok, _ := c.Get(ctx, "miss*:other")
+			assert.True(t, ok)
+			_, ok, _ = c.Get(ctx, "character:goku")
+			assert.True(t, ok)
+		})
+	}
+}
+
+func TestRedis_Concurrent(t *testing.T) {
+	server, err := resptest.NewServer()
+	require.NoError(t, err)
+	defer server.Close()
+
+	c := cache.NewRedis(cache.RedisOptions{Addr: server.Addr(), PoolSize: 2})
+	defer c.Close()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("key:%d", i)
+			assert.NoError(t, c.Set(ctx, key, []byte(key), 0))
+			value, ok, err := c.Get(ctx, key)
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, key, string(value))
+		}(i)
+	}
+	wg.Wait()
+
+	keys := server.Keys()
+	sort.Strings(keys)
+	assert.Len(t, keys, 20)
+}
+
+func TestRedis_Auth(t *testing.T) {
+	server, err := resptest.NewServer(resptest.WithPassword("s3cret"))
+	require.NoError(t, err)
+	defer server.Close()
+	ctx := context.Background()
+
+	c := cache.NewRedis(cache.RedisOptions{Addr: server.Addr(), Password: "s3cret", DB: 1})
+	defer c.Close()
+	assert.NoError(t, c.Ping(ctx))
+
+	wrong := cache.NewRedis(cache.RedisOptions{Addr: server.Addr(), Password: "wrong"})
+	defer wrong.Close()
+	var redisErr cache.RedisError
+	assert.ErrorAs(t, wrong.Ping(ctx), &redisErr)
+
+	anonymous := cache.NewRedis(cache.RedisOptions{Addr: server.Addr()})
+	defer anonymous.Close()
+	assert.ErrorAs(t, anonymous.Ping(ctx), &redisErr)
+}
+
+func TestRedis_Unreachable(t *testing.T) {
+	server, err := resptest.NewServer()
+	require.NoError(t, err)
+	addr := server.Addr()
+	server.Close()
+
+	c := cache.NewRedis(cache.RedisOptions{Addr: addr})
+	defer c.Close()
+
+	_, _, err = c.Get(context.Background(), "key")
+	assert.Error(t, err)
+}
+
+func TestRedis_ReconnectsAfterServerClosesConnection(t *testing.T) {
+	server, err := resptest.NewServer()
+	require.NoError(t, err)
+	defer server.Close()
+
+	c := cache.NewRedis(cache.RedisOptions{Addr: server.Addr()})
+	defer c.Close()
+	ctx := context.Background()
+
+	assert.NoError(t, c.Ping(ctx))
+
+	// The pooled connection is broken, the command fails once and is not reused
+	server.DropConnections()
+	_ = c.Ping(ctx)
+	assert.NoError(t, c.Ping(ctx))
+}
+
+func TestMemory_Bounded(t *testing.T) {
+	c := cache.NewMemory(2)
+	ctx := context.Background()
+
+	assert.NoError(t, c.Set(ctx, "a", []byte("1"), 0))
+	assert.NoError(t, c.Set(ctx, "b", []byte("1"), 0))
+	assert.NoError(t, c.Set(ctx, "c", []byte("1"), 0))
+
+	_, ok, _ := c.Get(ctx, "c")
+	assert.False(t, ok)
+	_, ok, _ = c.Get(ctx, "a")
+	assert.True(t, ok)
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMemoryMaxEntries bounds the keys of the in-memory cache
+const DefaultMemoryMaxEntries = 10000
+
+type memoryEntry struct {
+	value   []byte
+	expires time.Time // Zero never expires
+}
+
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && now.After(e.expires)
+}
+
+// Memory is a Cache local to the process. It is safe for concurrent use.
+type Memory struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]memoryEntry
+}
+
+// NewMemory keeps at most maxEntries keys. When it is full the expired
+// keys are dropped, and if there are none new keys are ignored.
+func NewMemory(maxEntries int) *Memory {
+	return &Memory{
+		maxEntries: maxEntries,
+		entries:    make(map[string]memoryEntry),
+	}
+}
+
+func (m *Memory) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if entry.expired(time.Now()) {
+		delete(m.entries, key)
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+func (m *Memory) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	entry := memoryEntry{value: append([]byte(nil), value...)}
+	now := time.Now()
+	if ttl > 0 {
+		entry.expires = now.Add(ttl)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.entries[key]; !ok && len(m.entries) >= m.maxEntries {
+		for k, e := range m.entries {
+			if e.expired(now) {
+				delete(m.entries, k)
+			}
+		}
+		if len(m.entries) >= m.maxEntries {
+			return nil
+		}
+	}
+	m.entries[key] = entry
+	return nil
+}
+
+func (m *Memory) Delete(_ context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		delete(m.entries, key)
+	}
+	return nil
+}
+
+func (m *Memory) DeletePrefix(_ context.Context, prefix string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var removed int64
+	now := time.Now()
+	for key, entry := range m.entries {
+		if strings.HasPrefix(key, prefix) {
+			if !entry.expired(now) {
+				removed++
+			}
+			delete(m.entries, key)
+		}
+	}
+	return removed, nil
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
@@ -0,0 +1,301 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default values of the Redis options
+const (
+	DefaultRedisPoolSize    = 10
+	DefaultRedisDialTimeout = 2 * time.Second
+	DefaultRedisTimeout     = time.Second
+)
+
+// scanCount is the number of keys asked on each SCAN
+const scanCount = 100
+
+// RedisError is an error reply of the server
+type RedisError string
+
+func (e RedisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// RedisOptions configures the connection to a server that speaks the Redis protocol
+type RedisOptions struct {
+	Addr     string
+	Password string
+	DB       int
+
+	PoolSize    int           // Idle connections kept open
+	DialTimeout time.Duration // Max duration to open a connection
+	Timeout     time.Duration // Max duration of a command if the context has no deadline
+}
+
+// Redis is a Cache stored in a Redis server (or any server speaking RESP2),
+// so it is shared by every replica of the api
+type Redis struct {
+	opts RedisOptions
+	idle chan *redisConn
+}
+
+type redisConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// NewRedis connects lazily, the first command opens the first connection
+func NewRedis(opts RedisOptions) *Redis {
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = DefaultRedisPoolSize
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = DefaultRedisDialTimeout
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultRedisTimeout
+	}
+	return &Redis{
+		opts: opts,
+		idle: make(chan *redisConn, opts.PoolSize),
+	}
+}
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := r.do(ctx, "GET", key)
+	if err != nil || reply == nil {
+		return nil, false, err
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("redis: unexpected reply %T to GET", reply)
+	}
+	return value, true, nil
+}
+
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []string{"SET", key, string(value)}
+	if ttl > 0 {
+		// Redis rejects PX 0, a ttl under a millisecond is rounded up
+		args = append(args, "PX", strconv.FormatInt(max(ttl.Milliseconds(), 1), 10))
+	}
+	_, err := r.do(ctx, args...)
+	return err
+}
+
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	_, err := r.do(ctx, append([]string{"DEL"}, keys...)...)
+	return err
+}
+
+func (r *Redis) DeletePrefix(ctx context.Context, prefix string) (int64, error) {
+	var removed int64
+	cursor := "0"
+	for {
+		reply, err := r.do(ctx, "SCAN", cursor, "MATCH", escapeGlob(prefix)+"*", "COUNT", strconv.Itoa(scanCount))
+		if err != nil {
+			return removed, err
+		}
+
+		page, ok := reply.([]interface{})
+		if !ok || len(page) != 2 {
+			return removed, fmt.Errorf("redis: unexpected reply %T to SCAN", reply)
+		}
+		next, _ := page[0].([]byte)
+		keys, _ := page[1].([]interface{})
+
+		if len(keys) > 0 {
+			args := make([]string, 0, len(keys)+1)
+			args = append(args, "DEL")
+			for _, key := range keys {
+				if k, ok := key.([]byte); ok {
+					args = append(args, string(k))
+				}
+			}
+			reply, err := r.do(ctx, args...)
+			if err != nil {
+				return removed, err
+			}
+			if n, ok := reply.(int64); ok {
+				removed += n
+			}
+		}
+
+		cursor = string(next)
+		if cursor == "0" || cursor == "" {
+			return removed, nil
+		}
+	}
+}
+
+// Ping checks the connection with the server
+func (r *Redis) Ping(ctx context.Context) error {
+	_, err := r.do(ctx, "PING")
+	return err
+}
+
+// Close closes the idle connections
+func (r *Redis) Close() error {
+	for {
+		select {
+		case conn := <-r.idle:
+			_ = conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// do sends a command and reads its reply. Nil replies are returned as nil,
+// bulk strings as []byte, integers as int64 and arrays as []interface{}.
+func (r *Redis) do(ctx context.Context, args ...string) (interface{}, error) {
+	conn, err := r.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := conn.roundTrip(ctx, r.opts.Timeout, args)
+	var redisErr RedisError
+	if err != nil && !errors.As(err, &redisErr) {
+		// The connection may be in the middle of a reply
+		_ = conn.Close()
+		return nil, err
+	}
+
+	r.release(conn)
+	return reply, err
+}
+
+// conn takes an idle connection or opens a new one
+func (r *Redis) conn(ctx context.Context) (*redisConn, error) {
+	select {
+	case conn := <-r.idle:
+		return conn, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: r.opts.DialTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", r.opts.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("redis: failed to connect: %w", err)
+	}
+	conn := &redisConn{Conn: netConn, reader: bufio.NewReader(netConn)}
+
+	if r.opts.Password != "" {
+		if _, err := conn.roundTrip(ctx, r.opts.Timeout, []string{"AUTH", r.opts.Password}); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	if r.opts.DB != 0 {
+		if _, err := conn.roundTrip(ctx, r.opts.Timeout, []string{"SELECT", strconv.Itoa(r.opts.DB)}); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// release puts the connection back in the pool, or closes it if the pool is full
+func (r *Redis) release(conn *redisConn) {
+	select {
+	case r.idle <- conn:
+	default:
+		_ = conn.Close()
+	}
+}
+
+func (c *redisConn) roundTrip(ctx context.Context, timeout time.Duration, args []string) (interface{}, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(timeout)
+	}
+	if err := c.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if _, err := c.Write(encodeCommand(args)); err != nil {
+		return nil, fmt.Errorf("redis: failed to send command: %w", err)
+	}
+	return readReply(c.reader)
+}
+
+// encodeCommand writes the command as an array of bulk strings
+func encodeCommand(args []string) []byte {
+	var b strings.Builder
+	b.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		b.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n")
+		b.WriteString(arg)
+		b.WriteString("\r\n")
+	}
+	return []byte(b.String())
+}
+
+// readReply parses a RESP2 reply
+func readReply(reader *bufio.Reader) (interface{}, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("redis: failed to read reply: %w", err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, RedisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		value := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, value); err != nil {
+			return nil, fmt.Errorf("redis: failed to read reply: %w", err)
+		}
+		return value[:size], nil
+	case '*':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, size)
+		for i := range items {
+			if items[i], err = readReply(reader); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply %q", line)
+	}
+}
+
+// escapeGlob escapes the characters with a meaning in a MATCH pattern
+func escapeGlob(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if strings.ContainsRune(`*?[]\`, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,311 @@
+// Package resptest provides an in-process server speaking the Redis
+// protocol (RESP2), so the Redis cache can be tested without a real Redis.
+// It supports PING, AUTH, SELECT, GET, SET (with EX and PX), DEL, EXISTS,
+// SCAN (MATCH and COUNT are accepted, every key is returned at once) and FLUSHALL.
+package resptest
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	value   string
+	expires time.Time
+}
+
+// Server is a RESP server listening on a local port
+type Server struct {
+	password string // Required with AUTH if not empty
+
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu       sync.Mutex
+	data     map[string]entry
+	commands int
+	conns    map[net.Conn]struct{}
+	closed   bool
+}
+
+// Option configures the server
+type Option func(*Server)
+
+// WithPassword requires the clients to send AUTH with the password
+func WithPassword(password string) Option {
+	return func(s *Server) {
+		s.password = password
+	}
+}
+
+// NewServer starts a server on a random local port
+func NewServer(opts ...Option) (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		listener: listener,
+		data:     make(map[string]entry),
+		conns:    make(map[net.Conn]struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the address to connect to
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Commands returns how many commands the server has received
+func (s *Server) Commands() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.commands
+}
+
+// Keys returns the keys that have not expired
+func (s *Server) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.data))
+	for key := range s.data {
+		if s.alive(key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// DropConnections closes the open connections, like a server restart would
+func (s *Server) DropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+}
+
+// Close stops the server and closes every connection
+func (s *Server) Close() {
+	s.mu.Lock()
+	s.closed = true
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+
+	_ = s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+	}()
+
+	reader := bufio.NewReader(conn)
+	authenticated := s.password == ""
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+
+		var reply string
+		switch {
+		case !authenticated && strings.ToUpper(args[0]) != "AUTH":
+			reply = "-NOAUTH Authentication required.\r\n"
+		case strings.ToUpper(args[0]) == "AUTH":
+			if len(args) == 2 && args[1] == s.password {
+				authenticated = true
+				reply = "+OK\r\n"
+			} else {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		default:
+			reply = s.exec(args)
+		}
+
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+// exec runs a command and returns the encoded reply
+func (s *Server) exec(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commands++
+
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "SELECT", "FLUSHALL":
+		if strings.ToUpper(args[0]) == "FLUSHALL" {
+			s.data = make(map[string]entry)
+		}
+		return "+OK\r\n"
+	case "GET":
+		if len(args) != 2 {
+			return wrongArgs(args[0])
+		}
+		if !s.alive(args[1]) {
+			return "$-1\r\n"
+		}
+		return bulk(s.data[args[1]].value)
+	case "SET":
+		if len(args) < 3 {
+			return wrongArgs(args[0])
+		}
+		e := entry{value: args[2]}
+		for i := 3; i+1 < len(args); i += 2 {
+			n, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil || n <= 0 {
+				return "-ERR invalid expire time in 'set' command\r\n"
+			}
+			switch strings.ToUpper(args[i]) {
+			case "EX":
+				e.expires = time.Now().Add(time.Duration(n) * time.Second)
+			case "PX":
+				e.expires = time.Now().Add(time.Duration(n) * time.Millisecond)
+			default:
+				return "-ERR syntax error\r\n"
+			}
+		}
+		s.data[args[1]] = e
+		return "+OK\r\n"
+	case "DEL", "EXISTS":
+		count := 0
+		for _, key := range args[1:] {
+			if s.alive(key) {
+				count++
+				if strings.ToUpper(args[0]) == "DEL" {
+					delete(s.data, key)
+				}
+			}
+		}
+		return ":" + strconv.Itoa(count) + "\r\n"
+	case "SCAN":
+		pattern := "*"
+		for i := 2; i+1 < len(args); i += 2 {
+			if strings.ToUpper(args[i]) == "MATCH" {
+				pattern = args[i+1]
+			}
+		}
+		var keys []string
+		for key := range s.data {
+			if ok, _ := path.Match(pattern, key); ok && s.alive(key) {
+				keys = append(keys, key)
+			}
+		}
+		reply := "*2\r\n" + bulk("0") + "*" + strconv.Itoa(len(keys)) + "\r\n"
+		for _, key := range keys {
+			reply += bulk(key)
+		}
+		return reply
+	default:
+		return "-ERR unknown command '" + args[0] + "'\r\n"
+	}
+}
+
+// alive reports whether the key exists and has not expired, the lock must be held
+func (s *Server) alive(key string) bool {
+	e, ok := s.data[key]
+	if !ok {
+		return false
+	}
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		delete(s.data, key)
+		return false
+	}
+	return true
+}
+
+func bulk(value string) string {
+	return "$" + strconv.Itoa(len(value)) + "\r\n" + value + "\r\n"
+}
+
+func wrongArgs(command string) string {
+	return "-ERR wrong number of arguments for '" + strings.ToLower(command) + "' command\r\n"
+}
+
+// readCommand reads an array of bulk strings
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if !strings.HasPrefix(line, "*") {
+		return nil, errors.New("expected an array")
+	}
+	count, err := strconv.Atoi(line[1:])
+	if err != nil || count < 1 {
+		return nil, errors.New("invalid array length")
+	}
+
+	args := make([]string, count)
+	for i := range args {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSuffix(line, "\r\n")
+		if !strings.HasPrefix(line, "$") {
+			return nil, errors.New("expected a bulk string")
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, errors.New("invalid bulk length")
+		}
+		value := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, value); err != nil {
+			return nil, err
+		}
+		args[i] = string(value[:size])
+	}
+	return args, nil
+}
@@ -0,0 +1,67 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Checkpoint is the progress of a sync. It is saved once the planets are
+// synced and after every page of characters, so an interrupted sync resumes
+// from the next page instead of starting over.
+type Checkpoint struct {
+	Name       string     `gorm:"primaryKey"`
+	NextPage   int        `gorm:"not null"`        // Next page of characters to sync
+	Report     Report     `gorm:"serializer:json"` // Counts so far
+	StartedAt  time.Time  `gorm:"not null"`        // When the sync started, not resumed
+	UpdatedAt  time.Time  // Last page synced
+	FinishedAt *time.Time // Nil while the sync is unfinished
+}
+
+func (Checkpoint) TableName() string {
+	return "sync_checkpoints"
+}
+
+// Finished reports whether the sync went through every page
+func (c *Checkpoint) Finished() bool {
+	return c.FinishedAt != nil
+}
+
+// CheckpointStore keeps the progress of the syncs
+type CheckpointStore interface {
+	// Load returns the checkpoint with the name, or nil if there is none
+	Load(ctx context.Context, name string) (*Checkpoint, error)
+	Save(ctx context.Context, checkpoint *Checkpoint) error
+}
+
+type checkpointStorage struct {
+	db *gorm.DB
+}
+
+// NewCheckpointStorage keeps the checkpoints in the database
+func NewCheckpointStorage(db *gorm.DB) CheckpointStore {
+	return &checkpointStorage{db}
+}
+
+func (s *checkpointStorage) Load(ctx context.Context, name string) (*Checkpoint, error) {
+	var checkpoint Checkpoint
+
+	err := s.db.WithContext(ctx).Where("name = ?", name).Take(&checkpoint).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+func (s *checkpointStorage) Save(ctx context.Context, checkpoint *Checkpoint) error {
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		UpdateAll: true,
+	}).Create(checkpoint).Error
+}
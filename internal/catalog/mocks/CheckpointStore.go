@@ -0,0 +1,78 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	catalog "github.com/gclamigueiro/dragon-ball-api/internal/catalog"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CheckpointStore is an autogenerated mock type for the CheckpointStore type
+type CheckpointStore struct {
+	mock.Mock
+}
+
+// Load provides a mock function with given fields: ctx, name
+func (_m *CheckpointStore) Load(ctx context.Context, name string) (*catalog.Checkpoint, error) {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Load")
+	}
+
+	var r0 *catalog.Checkpoint
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*catalog.Checkpoint, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *catalog.Checkpoint); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*catalog.Checkpoint)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: ctx, checkpoint
+func (_m *CheckpointStore) Save(ctx context.Context, checkpoint *catalog.Checkpoint) error {
+	ret := _m.Called(ctx, checkpoint)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *catalog.Checkpoint) error); ok {
+		r0 = rf(ctx, checkpoint)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewCheckpointStore creates a new instance of CheckpointStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCheckpointStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CheckpointStore {
+	mock := &CheckpointStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,31 @@
+package catalog
+
+// Counts tells what a sync did with the rows of a resource
+type Counts struct {
+	Added     int `json:"added"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Skipped   int `json:"skipped"` // Invalid data from the api, not saved
+}
+
+// count records a fetched row, comparing it with the saved one if it exists
+func (c *Counts) count(exists, same bool) {
+	switch {
+	case !exists:
+		c.Added++
+	case same:
+		c.Unchanged++
+	default:
+		c.Updated++
+	}
+}
+
+// Report is the result of a sync. A resumed sync includes the counts of the
+// run that was interrupted.
+type Report struct {
+	Planets         Counts `json:"planets"`
+	Characters      Counts `json:"characters"`
+	Transformations Counts `json:"transformations"`
+	Pages           int    `json:"pages"`   // Pages of characters synced
+	Resumed         bool   `json:"resumed"` // The sync continued an interrupted one
+}
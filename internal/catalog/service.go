@@ -0,0 +1,300 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/cache"
+	"github.com/gclamigueiro/dragon-ball-api/internal/character"
+	"github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
+	"github.com/gclamigueiro/dragon-ball-api/internal/planet"
+)
+
+// DefaultPageSize is how many rows are asked to the api per page
+const DefaultPageSize = 50
+
+// checkpointName is the name of the checkpoint of the catalog sync
+const checkpointName = "catalog"
+
+var (
+	ErrDatabase = errors.New("database error")
+
+	// ErrSyncInProgress is returned when a sync is started while another one runs
+	ErrSyncInProgress = errors.New("catalog sync already in progress")
+)
+
+type Service interface {
+	// Sync walks the whole catalog of the api and saves every planet,
+	// character and transformation, resuming an interrupted sync
+	Sync(ctx context.Context, opts SyncOptions) (*Report, error)
+}
+
+// SyncOptions changes how a sync runs
+type SyncOptions struct {
+	Restart bool // Start from the first page even if the last sync was interrupted
+}
+
+type service struct {
+	dgzClient   dragonball.Client
+	characters  character.Repository
+	planets     planet.Repository
+	checkpoints CheckpointStore
+	pageSize    int
+	cache       cache.Cache // Shared cache of the api, nil if disabled
+	logger      *slog.Logger
+
+	// running prevents two syncs from writing the same checkpoint
+	running sync.Mutex
+}
+
+// Option configures the optional behaviour of the service
+type Option func(*service)
+
+// WithPageSize sets how many rows are asked to the api per page
+func WithPageSize(size int) Option {
+	return func(s *service) {
+		s.pageSize = size
+	}
+}
+
+// WithCache sets the cache shared by the api replicas, the characters whose
+// data changed are removed from it so the api does not serve stale copies
+func WithCache(c cache.Cache) Option {
+	return func(s *service) {
+		s.cache = c
+	}
+}
+
+// WithLogger sets the logger of the service, slog.Default() if not set
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *service) {
+		s.logger = logger
+	}
+}
+
+func NewService(dgzClient dragonball.Client, characters character.Repository, planets planet.Repository, checkpoints CheckpointStore, opts ...Option) Service {
+	s := &service{
+		dgzClient:   dgzClient,
+		characters:  characters,
+		planets:     planets,
+		checkpoints: checkpoints,
+		pageSize:    DefaultPageSize,
+		logger:      slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Sync saves the planets first and then the characters, one page at a time.
+// The checkpoint is saved after every page, so if the sync is interrupted
+// the next one continues from the page that failed.
+func (s *service) Sync(ctx context.Context, opts SyncOptions) (*Report, error) {
+	if !s.running.TryLock() {
+		return nil, ErrSyncInProgress
+	}
+	defer s.running.Unlock()
+
+	checkpoint, err := s.checkpoints.Load(ctx, checkpointName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to load checkpoint: %w", ErrDatabase, err)
+	}
+
+	if checkpoint != nil && !checkpoint.Finished() && !opts.Restart {
+		checkpoint.Report.Resumed = true
+	} else {
+		checkpoint = &Checkpoint{Name: checkpointName, NextPage: 1, StartedAt: time.Now()}
+
+		// The planets are only a few pages, they are synced again from scratch
+		// until the first checkpoint is saved
+		if err := s.syncPlanets(ctx, &checkpoint.Report); err != nil {
+			return nil, err
+		}
+		if err := s.saveCheckpoint(ctx, checkpoint); err != nil {
+			return nil, err
+		}
+	}
+
+	for page := checkpoint.NextPage; ; page++ {
+		result, err := s.dgzClient.ListCharacters(ctx, page, s.pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch characters page %d: %w", page, err)
+		}
+
+		if err := s.syncCharacters(ctx, result.Items, &checkpoint.Report); err != nil {
+			return nil, fmt.Errorf("failed to sync characters page %d: %w", page, err)
+		}
+
+		checkpoint.NextPage = page + 1
+		checkpoint.Report.Pages++
+		done := len(result.Items) == 0 || !result.Meta.HasNext()
+		if done {
+			now := time.Now()
+			checkpoint.FinishedAt = &now
+		}
+		if err := s.saveCheckpoint(ctx, checkpoint); err != nil {
+			return nil, err
+		}
+		if done {
+			break
+		}
+	}
+
+	return &checkpoint.Report, nil
+}
+
+// syncPlanets walks every page of planets and saves them
+func (s *service) syncPlanets(ctx context.Context, report *Report) error {
+	saved, err := s.planets.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: failed to get planets: %w", ErrDatabase, err)
+	}
+	existing := make(map[int]*planet.Planet, len(saved))
+	for _, p := range saved {
+		existing[p.ID] = p
+	}
+
+	for page := 1; ; page++ {
+		result, err := s.dgzClient.ListPlanets(ctx, page, s.pageSize)
+		if err != nil {
+			return fmt.Errorf("failed to fetch planets page %d: %w", page, err)
+		}
+
+		planets := make([]*planet.Planet, 0, len(result.Items))
+		for _, item := range result.Items {
+			p := planet.FromAPIResponse(item)
+			if !p.IsValid() {
+				report.Planets.Skipped++
+				continue
+			}
+			old, ok := existing[p.ID]
+			report.Planets.count(ok, ok && old.SameData(p))
+			planets = append(planets, p)
+		}
+
+		if err := s.planets.SaveAll(ctx, planets); err != nil {
+			return fmt.Errorf("%w: failed to save planets page %d: %w", ErrDatabase, page, err)
+		}
+
+		if len(result.Items) == 0 || !result.Meta.HasNext() {
+			return nil
+		}
+	}
+}
+
+// syncCharacters fetches the detail of every character of a page, to know
+// its origin planet and transformations, and saves them
+func (s *service) syncCharacters(ctx context.Context, items []*dragonball.Character, report *Report) error {
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	saved, err := s.characters.FindByIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+	existing := make(map[int]*character.Character, len(saved))
+	for _, c := range saved {
+		existing[c.ID] = c
+	}
+
+	characters := make([]*character.Character, 0, len(items))
+	transformations := make(map[int][]*character.Transformation)
+	var stale []string // Cache keys of the characters whose data changed
+	for _, item := range items {
+		detail, err := s.dgzClient.GetCharacterByID(ctx, item.ID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch character %d: %w", item.ID, err)
+		}
+
+		// The character may be removed between the listing and the detail
+		char := character.FromAPIResponse(item)
+		if detail != nil {
+			char = character.FromAPIDetail(detail)
+		}
+		if !char.IsValid() {
+			report.Characters.Skipped++
+			continue
+		}
+		old, ok := existing[char.ID]
+		same := ok && old.SameData(char)
+		report.Characters.count(ok, same)
+		if ok && !same {
+			// The old name is removed too in case the character was renamed
+			stale = append(stale, character.NameCacheKey(old.Name), character.NameCacheKey(char.Name))
+		}
+		characters = append(characters, char)
+
+		if detail != nil {
+			fetched, err := s.countTransformations(ctx, char.ID, detail.Transformations, &report.Transformations)
+			if err != nil {
+				return err
+			}
+			transformations[char.ID] = fetched
+		}
+	}
+
+	if err := s.characters.SaveAll(ctx, characters); err != nil {
+		return fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+	for _, char := range characters {
+		fetched, ok := transformations[char.ID]
+		if !ok {
+			continue
+		}
+		if err := s.characters.SaveTransformations(ctx, char, fetched); err != nil {
+			return fmt.Errorf("%w: failed to save transformations of character %d: %w", ErrDatabase, char.ID, err)
+		}
+	}
+	s.invalidate(ctx, stale)
+	return nil
+}
+
+// invalidate removes the keys from the shared cache. It is best effort, the
+// errors are only logged: the page is saved and the keys expire on their own.
+func (s *service) invalidate(ctx context.Context, keys []string) {
+	if s.cache == nil || len(keys) == 0 {
+		return
+	}
+	if err := s.cache.Delete(ctx, keys...); err != nil {
+		s.logger.WarnContext(ctx, "failed to invalidate the cache", "keys", keys, "error", err)
+	}
+}
+
+// countTransformations maps the transformations of a character and counts
+// them against the saved ones, returning the valid ones
+func (s *service) countTransformations(ctx context.Context, characterID int, items []*dragonball.Transformation, counts *Counts) ([]*character.Transformation, error) {
+	saved, err := s.characters.FindTransformations(ctx, characterID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+	existing := make(map[int]*character.Transformation, len(saved))
+	for _, t := range saved {
+		existing[t.ID] = t
+	}
+
+	fetched := character.TransformationsFromAPIResponse(characterID, items)
+	valid := fetched[:0]
+	for _, t := range fetched {
+		if !t.IsValid() {
+			counts.Skipped++
+			continue
+		}
+		old, ok := existing[t.ID]
+		counts.count(ok, ok && old.SameData(t))
+		valid = append(valid, t)
+	}
+	return valid, nil
+}
+
+func (s *service) saveCheckpoint(ctx context.Context, checkpoint *Checkpoint) error {
+	if err := s.checkpoints.Save(ctx, checkpoint); err != nil {
+		return fmt.Errorf("%w: failed to save checkpoint: %w", ErrDatabase, err)
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package catalog_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/cache"
+	"github.com/gclamigueiro/dragon-ball-api/internal/catalog"
+	"github.com/gclamigueiro/dragon-ball-api/internal/catalog/mocks"
+	"github.com/gclamigueiro/dragon-ball-api/internal/character"
+	mock_character "github.com/gclamigueiro/dragon-ball-api/internal/character/mocks"
+	"github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
+	mock_dragonball "github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball/mocks"
+	"github.com/gclamigueiro/dragon-ball-api/internal/planet"
+	mock_planet "github.com/gclamigueiro/dragon-ball-api/internal/planet/mocks"
+)
+
+type syncMocks struct {
+	client      *mock_dragonball.Client
+	characters  *mock_character.Repository
+	planets     *mock_planet.Repository
+	checkpoints *mocks.CheckpointStore
+}
+
+func newSyncService(opts ...catalog.Option) (catalog.Service, *syncMocks) {
+	m := &syncMocks{
+		client:      new(mock_dragonball.Client),
+		characters:  new(mock_character.Repository),
+		planets:     new(mock_planet.Repository),
+		checkpoints: new(mocks.CheckpointStore),
+	}
+	opts = append([]catalog.Option{catalog.WithPageSize(2)}, opts...)
+	return catalog.NewService(m.client, m.characters, m.planets, m.checkpoints, opts...), m
+}
+
+func (m *syncMocks) assertExpectations(t *testing.T) {
+	m.client.AssertExpectations(t)
+	m.characters.AssertExpectations(t)
+	m.planets.AssertExpectations(t)
+	m.checkpoints.AssertExpectations(t)
+}
+
+func page(current, total int) dragonball.PageMeta {
+	return dragonball.PageMeta{CurrentPage: current, TotalPages: total}
+}
+
+func TestService_Sync(t *testing.T) {
+	svc, m := newSyncService()
+	ctx := context.Background()
+
+	m.checkpoints.On("Load", mock.Anything, "catalog").Return(nil, nil)
+
+	// Namek is saved with the same data, Vegeta is new
+	m.planets.On("FindAll", mock.Anything).Return([]*planet.Planet{{ID: 1, Name: "Namek"}}, nil)
+	m.client.On("ListPlanets", mock.Anything, 1, 2).Return(&dragonball.PlanetPage{
+		Items: []*dragonball.Planet{{ID: 1, Name: "Namek"}, {ID: 3, Name: "Vegeta", IsDestroyed: true}},
+		Meta:  page(1, 1),
+	}, nil)
+	m.planets.On("SaveAll", mock.Anything, mock.MatchedBy(func(planets []*planet.Planet) bool {
+		return len(planets) == 2
+	})).Return(nil)
+
+	// Goku changed, Vegeta is new, and then an empty last page
+	m.client.On("ListCharacters", mock.Anything, 1, 2).Return(&dragonball.CharacterPage{
+		Items: []*dragonball.Character{{ID: 1, Name: "Goku"}, {ID: 2, Name: "Vegeta"}},
+		Meta:  page(1, 2),
+	}, nil)
+	m.client.On("ListCharacters", mock.Anything, 2, 2).Return(&dragonball.CharacterPage{Meta: page(2, 2)}, nil)
+	m.characters.On("FindByIDs", mock.Anything, []int{1, 2}).
+		Return([]*character.Character{{ID: 1, Name: "Goku", Ki: "60.000.000"}}, nil)
+	m.characters.On("FindByIDs", mock.Anything, []int{}).Return(nil, nil)
+	m.client.On("GetCharacterByID", mock.Anything, 1).Return(&dragonball.CharacterDetail{
+		Character:       dragonball.Character{ID: 1, Name: "Goku", Ki: "70.000.000"},
+		Transformations: []*dragonball.Transformation{{ID: 1, Name: "Goku SSJ", Ki: "3 Billion"}},
+	}, nil)
+	m.client.On("GetCharacterByID", mock.Anything, 2).Return(&dragonball.CharacterDetail{
+		Character:    dragonball.Character{ID: 2, Name: "Vegeta"},
+		OriginPlanet: &dragonball.Planet{ID: 3, Name: "Vegeta"},
+	}, nil)
+	m.characters.On("FindTransformations", mock.Anything, 1).
+		Return([]*character.Transformation{{ID: 1, CharacterID: 1, Name: "Goku SSJ", Ki: "3 Billion", KiNumeric: character.NewPowerLevel(mustKi(t, "3 Billion"))}}, nil)
+	m.characters.On("FindTransformations", mock.Anything, 2).Return(nil, nil)
+	m.characters.On("SaveAll", mock.Anything, mock.Anything).Return(nil)
+	m.characters.On("SaveTransformations", mock.Anything, mock.MatchedBy(func(c *character.Character) bool {
+		return c.ID == 1
+	}), mock.MatchedBy(func(transformations []*character.Transformation) bool {
+		return len(transformations) == 1
+	})).Return(nil)
+	m.characters.On("SaveTransformations", mock.Anything, mock.MatchedBy(func(c *character.Character) bool {
+		return c.ID == 2 && *c.OriginPlanetID == 3
+	}), mock.Anything).Return(nil)
+
+	// Once after the planets and once per page
+	m.checkpoints.On("Save", mock.Anything, mock.Anything).Return(nil).Times(3)
+
+	report, err := svc.Sync(ctx, catalog.SyncOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, catalog.Counts{Added: 1, Unchanged: 1}, report.Planets)
+	assert.Equal(t, catalog.Counts{Added: 1, Updated: 1}, report.Characters)
+	assert.Equal(t, catalog.Counts{Unchanged: 1}, report.Transformations)
+	assert.Equal(t, 2, report.Pages)
+	assert.False(t, report.Resumed)
+
+	saved := m.checkpoints.Calls[len(m.checkpoints.Calls)-1].Arguments.Get(1).(*catalog.Checkpoint)
+	assert.Equal(t, 3, saved.NextPage)
+	assert.True(t, saved.Finished())
+	m.assertExpectations(t)
+}
+
+func TestService_Sync_ResumesInterruptedSync(t *testing.T) {
+	svc, m := newSyncService()
+	ctx := context.Background()
+
+	// The planets and the first page were synced before the interruption
+	m.checkpoints.On("Load", mock.Anything, "catalog").Return(&catalog.Checkpoint{
+		Name:      "catalog",
+		NextPage:  2,
+		StartedAt: time.Now(),
+		Report:    catalog.Report{Characters: catalog.Counts{Added: 2}, Pages: 1},
+	}, nil)
+
+	m.client.On("ListCharacters", mock.Anything, 2, 2).Return(&dragonball.CharacterPage{
+		Items: []*dragonball.Character{{ID: 3, Name: "Piccolo"}},
+		Meta:  page(2, 2),
+	}, nil)
+	m.characters.On("FindByIDs", mock.Anything, []int{3}).Return(nil, nil)
+	m.client.On("GetCharacterByID", mock.Anything, 3).Return(nil, nil)
+	m.characters.On("SaveAll", mock.Anything, mock.MatchedBy(func(characters []*character.Character) bool {
+		return len(characters) == 1 && characters[0].Name == "Piccolo"
+	})).Return(nil)
+	m.checkpoints.On("Save", mock.Anything, mock.MatchedBy(func(c *catalog.Checkpoint) bool {
+		return c.NextPage == 3 && c.Finished()
+	})).Return(nil).Once()
+
+	report, err := svc.Sync(ctx, catalog.SyncOptions{})
+	assert.NoError(t, err)
+	assert.True(t, report.Resumed)
+	assert.Equal(t, catalog.Counts{Added: 3}, report.Characters)
+	assert.Equal(t, 2, report.Pages)
+	m.planets.AssertNotCalled(t, "FindAll", mock.Anything)
+	m.assertExpectations(t)
+}
+
+func TestService_Sync_InvalidatesChangedCharacters(t *testing.T) {
+	shared := cache.NewMemory(10)
+	svc, m := newSyncService(catalog.WithCache(shared))
+	ctx := context.Background()
+
+	for _, name := range []string{"Kakarotto", "Goku", "Piccolo"} {
+		assert.NoError(t, shared.Set(ctx, character.NameCacheKey(name), []byte("{}"), time.Hour))
+	}
+
+	// Kakarotto was renamed to Goku, Piccolo did not change
+	m.checkpoints.On("Load", mock.Anything, "catalog").Return(&catalog.Checkpoint{Name: "catalog", NextPage: 2}, nil)
+	m.client.On("ListCharacters", mock.Anything, 2, 2).Return(&dragonball.CharacterPage{
+		Items: []*dragonball.Character{{ID: 1, Name: "Goku"}, {ID: 3, Name: "Piccolo"}},
+		Meta:  page(2, 2),
+	}, nil)
+	m.characters.On("FindByIDs", mock.Anything, []int{1, 3}).
+		Return([]*character.Character{{ID: 1, Name: "Kakarotto"}, {ID: 3, Name: "Piccolo"}}, nil)
+	m.client.On("GetCharacterByID", mock.Anything, mock.Anything).Return(nil, nil)
+	m.characters.On("SaveAll", mock.Anything, mock.Anything).Return(nil)
+	m.checkpoints.On("Save", mock.Anything, mock.Anything).Return(nil).Once()
+
+	_, err := svc.Sync(ctx, catalog.SyncOptions{})
+	assert.NoError(t, err)
+
+	for name, cached := range map[string]bool{"Kakarotto": false, "Goku": false, "Piccolo": true} {
+		_, ok, err := shared.Get(ctx, character.NameCacheKey(name))
+		assert.NoError(t, err)
+		assert.Equal(t, cached, ok, name)
+	}
+	m.assertExpectations(t)
+}
+
+func TestService_Sync_KeepsCacheOnFailure(t *testing.T) {
+	shared := cache.NewMemory(10)
+	svc, m := newSyncService(catalog.WithCache(shared))
+	ctx := context.Background()
+
+	assert.NoError(t, shared.Set(ctx, character.NameCacheKey("Goku"), []byte("{}"), time.Hour))
+
+	// The cached copy is still the saved one if the page cannot be saved
+	m.checkpoints.On("Load", mock.Anything, "catalog").Return(&catalog.Checkpoint{Name: "catalog", NextPage: 2}, nil)
+	m.client.On("ListCharacters", mock.Anything, 2, 2).Return(&dragonball.CharacterPage{
+		Items: []*dragonball.Character{{ID: 1, Name: "Goku", Ki: "70.000.000"}},
+		Meta:  page(2, 2),
+	}, nil)
+	m.characters.On("FindByIDs", mock.Anything, []int{1}).
+		Return([]*character.Character{{ID: 1, Name: "Goku", Ki: "60.000.000"}}, nil)
+	m.client.On("GetCharacterByID", mock.Anything, 1).Return(nil, nil)
+	m.characters.On("SaveAll", mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+
+	_, err := svc.Sync(ctx, catalog.SyncOptions{})
+	assert.ErrorIs(t, err, catalog.ErrDatabase)
+
+	_, ok, _ := shared.Get(ctx, character.NameCacheKey("Goku"))
+	assert.True(t, ok)
+	m.assertExpectations(t)
+}
+
+func TestService_Sync_Restart(t *testing.T) {
+	svc, m := newSyncService()
+	ctx := context.Background()
+
+	m.checkpoints.On("Load", mock.Anything, "catalog").Return(&catalog.Checkpoint{Name: "catalog", NextPage: 5}, nil)
+	m.planets.On("FindAll", mock.Anything).Return(nil, nil)
+	m.client.On("ListPlanets", mock.Anything, 1, 2).Return(&dragonball.PlanetPage{Meta: page(1, 1)}, nil)
+	m.planets.On("SaveAll", mock.Anything, mock.Anything).Return(nil)
+	m.client.On("ListCharacters", mock.Anything, 1, 2).Return(&dragonball.CharacterPage{Meta: page(1, 1)}, nil)
+	m.characters.On("FindByIDs", mock.Anything, []int{}).Return(nil, nil)
+	m.characters.On("SaveAll", mock.Anything, mock.Anything).Return(nil)
+	m.checkpoints.On("Save", mock.Anything, mock.Anything).Return(nil).Twice()
+
+	report, err := svc.Sync(ctx, catalog.SyncOptions{Restart: true})
+	assert.NoError(t, err)
+	assert.False(t, report.Resumed)
+	assert.Equal(t, 1, report.Pages)
+	m.assertExpectations(t)
+}
+
+func TestService_Sync_KeepsCheckpointOnFailure(t *testing.T) {
+	svc, m := newSyncService()
+	ctx := context.Background()
+
+	m.checkpoints.On("Load", mock.Anything, "catalog").Return(&catalog.Checkpoint{Name: "catalog", NextPage: 2}, nil)
+	m.client.On("ListCharacters", mock.Anything, 2, 2).Return(nil, dragonball.ErrUnavailable)
+
+	_, err := svc.Sync(ctx, catalog.SyncOptions{})
+	assert.ErrorIs(t, err, dragonball.ErrUnavailable)
+	m.checkpoints.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	m.assertExpectations(t)
+}
+
+func TestService_Sync_DatabaseError(t *testing.T) {
+	svc, m := newSyncService()
+	ctx := context.Background()
+
+	m.checkpoints.On("Load", mock.Anything, "catalog").Return(nil, errors.New("connection refused"))
+
+	_, err := svc.Sync(ctx, catalog.SyncOptions{})
+	assert.ErrorIs(t, err, catalog.ErrDatabase)
+	m.assertExpectations(t)
+}
+
+func mustKi(t *testing.T, raw string) *big.Int {
+	t.Helper()
+	ki, err := character.ParseKi(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ki
+}
@@ -0,0 +1,241 @@
+package character
+
+import (
+	"container/list"
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheStats are the counters of the repository cache
+type CacheStats struct {
+	Hits          int64 `json:"hits"`
+	Misses        int64 `json:"misses"`
+	Evictions     int64 `json:"evictions"`     // Entries dropped to make room for new ones
+	Invalidations int64 `json:"invalidations"` // Entries dropped because a character was saved
+	Entries       int   `json:"entries"`
+}
+
+// cacheEntry is the result of a FindByID or FindByName lookup.
+// A nil character is cached too, the lookup found nothing.
+type cacheEntry struct {
+	key       string
+	id        int       // Set for the lookups by id
+	name      string    // Set for the lookups by name
+	mode      MatchMode // Set for the lookups by name
+	character *Character
+	expires   time.Time
+}
+
+// CachedRepository is a Repository that keeps the lookups by id and by name
+// of another one in a bounded LRU cache. Saving a character invalidates
+// every entry it could change. Changes made by other replicas or bypassing
+// the repository are only seen once the entries expire.
+type CachedRepository struct {
+	Repository
+
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	lru     *list.List // Most recently used first
+	entries map[string]*list.Element
+	stats   CacheStats
+
+	// generation changes on every invalidation, so a lookup that raced
+	// with a save does not cache what it read before the save
+	generation uint64
+}
+
+// NewCachedRepository caches up to size lookups of the repository for the ttl
+func NewCachedRepository(repository Repository, size int, ttl time.Duration) *CachedRepository {
+	return &CachedRepository{
+		Repository: repository,
+		size:       size,
+		ttl:        ttl,
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+func (r *CachedRepository) FindByID(ctx context.Context, id int) (*Character, error) {
+	key := "id:" + strconv.Itoa(id)
+	character, generation, ok := r.get(key)
+	if ok {
+		return character, nil
+	}
+
+	character, err := r.Repository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.put(&cacheEntry{key: key, id: id, character: character}, generation)
+	return copyCharacter(character), nil
+}
+
+func (r *CachedRepository) FindByName(ctx context.Context, name string, mode MatchMode) (*Character, error) {
+	key := "name:" + string(mode) + ":" + strings.ToLower(name)
+	character, generation, ok := r.get(key)
+	if ok {
+		return character, nil
+	}
+
+	character, err := r.Repository.FindByName(ctx, name, mode)
+	if err != nil {
+		return nil, err
+	}
+	r.put(&cacheEntry{key: key, name: name, mode: mode, character: character}, generation)
+	return copyCharacter(character), nil
+}
+
+func (r *CachedRepository) Save(ctx context.Context, character *Character) error {
+	defer r.invalidate(character)
+	return r.Repository.Save(ctx, character)
+}
+
+func (r *CachedRepository) SaveAll(ctx context.Context, characters []*Character) error {
+	defer r.invalidate(characters...)
+	return r.Repository.SaveAll(ctx, characters)
+}
+
+func (r *CachedRepository) SaveTransformations(ctx context.Context, character *Character, transformations []*Transformation) error {
+	defer r.invalidate(character)
+	return r.Repository.SaveTransformations(ctx, character, transformations)
+}
+
+// Stats returns a snapshot of the counters
+func (r *CachedRepository) Stats() CacheStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := r.stats
+	stats.Entries = r.lru.Len()
+	return stats
+}
+
+// get returns a copy of the cached character, so callers cannot change the cache.
+// On a miss it returns the generation to pass to put.
+func (r *CachedRepository) get(key string) (*Character, uint64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	element, ok := r.entries[key]
+	if !ok {
+		r.stats.Misses++
+		return nil, r.generation, false
+	}
+
+	entry := element.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		r.remove(element)
+		r.stats.Misses++
+		return nil, r.generation, false
+	}
+
+	r.lru.MoveToFront(element)
+	r.stats.Hits++
+	return copyCharacter(entry.character), r.generation, true
+}
+
+// put caches the entry unless something was saved since the generation
+func (r *CachedRepository) put(entry *cacheEntry, generation uint64) {
+	if r.size <= 0 {
+		return
+	}
+	entry.expires = time.Now().Add(r.ttl)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if generation != r.generation {
+		return
+	}
+
+	if element, ok := r.entries[entry.key]; ok {
+		element.Value = entry
+		r.lru.MoveToFront(element)
+		return
+	}
+
+	r.entries[entry.key] = r.lru.PushFront(entry)
+	for r.lru.Len() > r.size {
+		r.remove(r.lru.Back())
+		r.stats.Evictions++
+	}
+}
+
+// invalidate drops the entries of the saved characters and the lookups by
+// name they would now match, including the ones that found nothing
+func (r *CachedRepository) invalidate(characters ...*Character) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.generation++
+
+	for element := r.lru.Front(); element != nil; {
+		next := element.Next()
+		entry := element.Value.(*cacheEntry)
+		for _, character := range characters {
+			if entry.affectedBy(character) {
+				r.remove(element)
+				r.stats.Invalidations++
+				break
+			}
+		}
+		element = next
+	}
+}
+
+// remove drops the element, the lock must be held
+func (r *CachedRepository) remove(element *list.Element) {
+	r.lru.Remove(element)
+	delete(r.entries, element.Value.(*cacheEntry).key)
+}
+
+// affectedBy reports whether saving the character can change the entry
+func (e *cacheEntry) affectedBy(character *Character) bool {
+	if character == nil {
+		return false
+	}
+	if e.character != nil && e.character.ID == character.ID {
+		return true
+	}
+	if e.id != 0 {
+		return e.id == character.ID
+	}
+	return e.mode.Matches(e.name, character.Name)
+}
+
+// copyCharacter returns a deep copy, so the callers cannot modify the cached values
+func copyCharacter(character *Character) *Character {
+	if character == nil {
+		return nil
+	}
+	c := *character
+	c.KiNumeric = copyPowerLevel(character.KiNumeric)
+	c.MaxKiNumeric = copyPowerLevel(character.MaxKiNumeric)
+	c.DeletedAt = copyPointer(character.DeletedAt)
+	c.OriginPlanetID = copyPointer(character.OriginPlanetID)
+	c.UnparsedKi = slices.Clone(character.UnparsedKi)
+	return &c
+}
+
+// copyPowerLevel copies the value, big.Int cannot be copied by assignment
+func copyPowerLevel(p *PowerLevel) *PowerLevel {
+	if p == nil {
+		return nil
+	}
+	c := &PowerLevel{null: p.null}
+	c.Set(&p.Int)
+	return c
+}
+
+func copyPointer[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	return &c
+}
@@ -0,0 +1,198 @@
+package character_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/character"
+	"github.com/gclamigueiro/dragon-ball-api/internal/character/mocks"
+)
+
+func TestCachedRepository_FindByName(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	repo := character.NewCachedRepository(mockRepo, 10, time.Minute)
+	ctx := context.Background()
+
+	mockRepo.On("FindByName", mock.Anything, "Goku", character.MatchExact).Return(&character.Character{ID: 1, Name: "Goku"}, nil).Once()
+
+	for i := 0; i < 3; i++ {
+		result, err := repo.FindByName(ctx, "Goku", character.MatchExact)
+		assert.NoError(t, err)
+		assert.Equal(t, "Goku", result.Name)
+	}
+
+	// The cached value cannot be changed by the callers
+	result, _ := repo.FindByName(ctx, "goku", character.MatchExact)
+	result.Name = "Kakarot"
+	result, _ = repo.FindByName(ctx, "Goku", character.MatchExact)
+	assert.Equal(t, "Goku", result.Name)
+
+	stats := repo.Stats()
+	assert.Equal(t, int64(1), stats.Misses)
+	assert.Equal(t, int64(4), stats.Hits)
+	assert.Equal(t, 1, stats.Entries)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCachedRepository_ReturnsDeepCopies(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	repo := character.NewCachedRepository(mockRepo, 10, time.Minute)
+	ctx := context.Background()
+
+	deletedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	planetID := 3
+	saved := &character.Character{
+		ID: 1, Name: "Goku", Ki: "60.000.000", MaxKi: "90 Septillion",
+		DeletedAt: &deletedAt, OriginPlanetID: &planetID,
+	}
+	saved.ParseKi()
+	mockRepo.On("FindByID", mock.Anything, 1).Return(saved, nil).Once()
+
+	result, err := repo.FindByID(ctx, 1)
+	assert.NoError(t, err)
+	result.KiNumeric.SetInt64(1)
+	result.MaxKiNumeric.SetInt64(1)
+	*result.DeletedAt = time.Now()
+	*result.OriginPlanetID = 99
+
+	result, err = repo.FindByID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "60000000", result.KiNumeric.String())
+	assert.Equal(t, "90000000000000000000000000", result.MaxKiNumeric.String())
+	assert.Equal(t, deletedAt, *result.DeletedAt)
+	assert.Equal(t, 3, *result.OriginPlanetID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCachedRepository_CachesNotFound(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	repo := character.NewCachedRepository(mockRepo, 10, time.Minute)
+	ctx := context.Background()
+
+	mockRepo.On("FindByID", mock.Anything, 99).Return(nil, nil).Once()
+
+	for i := 0; i < 2; i++ {
+		result, err := repo.FindByID(ctx, 99)
+		assert.NoError(t, err)
+		assert.Nil(t, result)
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCachedRepository_DoesNotCacheErrors(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	repo := character.NewCachedRepository(mockRepo, 10, time.Minute)
+	ctx := context.Background()
+
+	mockRepo.On("FindByID", mock.Anything, 1).Return(nil, errors.New("db error")).Twice()
+
+	for i := 0; i < 2; i++ {
+		_, err := repo.FindByID(ctx, 1)
+		assert.Error(t, err)
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCachedRepository_Expires(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	repo := character.NewCachedRepository(mockRepo, 10, 10*time.Millisecond)
+	ctx := context.Background()
+
+	mockRepo.On("FindByID", mock.Anything, 1).Return(&character.Character{ID: 1, Name: "Goku"}, nil).Twice()
+
+	_, _ = repo.FindByID(ctx, 1)
+	time.Sleep(20 * time.Millisecond)
+	_, _ = repo.FindByID(ctx, 1)
+
+	assert.Equal(t, int64(2), repo.Stats().Misses)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCachedRepository_EvictsLeastRecentlyUsed(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	repo := character.NewCachedRepository(mockRepo, 2, time.Minute)
+	ctx := context.Background()
+
+	mockRepo.On("FindByID", mock.Anything, 1).Return(&character.Character{ID: 1, Name: "Goku"}, nil).Once()
+	mockRepo.On("FindByID", mock.Anything, 2).Return(&character.Character{ID: 2, Name: "Vegeta"}, nil).Twice()
+	mockRepo.On("FindByID", mock.Anything, 3).Return(&character.Character{ID: 3, Name: "Piccolo"}, nil).Once()
+
+	_, _ = repo.FindByID(ctx, 1)
+	_, _ = repo.FindByID(ctx, 2)
+	_, _ = repo.FindByID(ctx, 1) // 2 is now the least recently used
+	_, _ = repo.FindByID(ctx, 3)
+	_, _ = repo.FindByID(ctx, 1)
+	_, _ = repo.FindByID(ctx, 2)
+
+	assert.Equal(t, int64(2), repo.Stats().Evictions)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCachedRepository_SaveInvalidates(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	repo := character.NewCachedRepository(mockRepo, 10, time.Minute)
+	ctx := context.Background()
+
+	gohan := &character.Character{ID: 5, Name: "Gohan"}
+	mockRepo.On("FindByName", mock.Anything, "Go", character.MatchPrefix).Return(nil, nil).Once()
+	mockRepo.On("FindByName", mock.Anything, "Gohan", character.MatchExact).Return(nil, nil).Once()
+	mockRepo.On("FindByName", mock.Anything, "Vegeta", character.MatchExact).Return(&character.Character{ID: 2, Name: "Vegeta"}, nil).Once()
+	mockRepo.On("FindByID", mock.Anything, 5).Return(nil, nil).Once()
+	mockRepo.On("SaveAll", mock.Anything, []*character.Character{gohan}).Return(nil)
+
+	_, _ = repo.FindByName(ctx, "Go", character.MatchPrefix)
+	_, _ = repo.FindByName(ctx, "Gohan", character.MatchExact)
+	_, _ = repo.FindByName(ctx, "Vegeta", character.MatchExact)
+	_, _ = repo.FindByID(ctx, 5)
+
+	assert.NoError(t, repo.SaveAll(ctx, []*character.Character{gohan}))
+
+	// Every lookup Gohan matches is gone, Vegeta is kept
+	stats := repo.Stats()
+	assert.Equal(t, int64(3), stats.Invalidations)
+	assert.Equal(t, 1, stats.Entries)
+
+	mockRepo.On("FindByName", mock.Anything, "Go", character.MatchPrefix).Return(gohan, nil).Once()
+	result, err := repo.FindByName(ctx, "Go", character.MatchPrefix)
+	assert.NoError(t, err)
+	assert.Equal(t, "Gohan", result.Name)
+
+	_, _ = repo.FindByName(ctx, "Vegeta", character.MatchExact)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCachedRepository_SaveTransformationsInvalidates(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	repo := character.NewCachedRepository(mockRepo, 10, time.Minute)
+	ctx := context.Background()
+
+	goku := &character.Character{ID: 1, Name: "Goku"}
+	fetched := &character.Character{ID: 1, Name: "Goku", TransformationsFetched: true}
+	mockRepo.On("FindByID", mock.Anything, 1).Return(goku, nil).Once()
+	mockRepo.On("SaveTransformations", mock.Anything, goku, mock.Anything).Return(nil)
+
+	_, _ = repo.FindByID(ctx, 1)
+	assert.NoError(t, repo.SaveTransformations(ctx, goku, nil))
+
+	mockRepo.On("FindByID", mock.Anything, 1).Return(fetched, nil).Once()
+	result, _ := repo.FindByID(ctx, 1)
+	assert.True(t, result.TransformationsFetched)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCachedRepository_Disabled(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	repo := character.NewCachedRepository(mockRepo, 0, time.Minute)
+	ctx := context.Background()
+
+	mockRepo.On("FindByID", mock.Anything, 1).Return(&character.Character{ID: 1}, nil).Twice()
+
+	_, _ = repo.FindByID(ctx, 1)
+	_, _ = repo.FindByID(ctx, 1)
+	mockRepo.AssertExpectations(t)
+}
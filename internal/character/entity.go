@@ -1,12 +1,112 @@
 package character
 
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
 type Character struct {
-	ID   int    `gorm:"primaryKey;not null" json:"id"`         // Required by DB
-	Name string `gorm:"not null;check:name <> ''" json:"name"` // Required and non-empty string
-	Ki   string `json:"ki"`
-	Race string `json:"race"`
+	ID           int         `gorm:"primaryKey;not null" json:"id"`         // Required by DB
+	Name         string      `gorm:"not null;check:name <> ''" json:"name"` // Required and non-empty string
+	Ki           string      `json:"ki"`
+	KiNumeric    *PowerLevel `gorm:"type:numeric" json:"ki_numeric"` // Nil when Ki could not be parsed
+	MaxKi        string      `json:"max_ki"`
+	MaxKiNumeric *PowerLevel `gorm:"type:numeric" json:"max_ki_numeric"` // Nil when MaxKi could not be parsed
+	Race         string      `json:"race"`
+	Gender       string      `json:"gender"`
+	Description  string      `json:"description"`
+	Image        string      `json:"image"`
+	Affiliation  string      `json:"affiliation"`
+	DeletedAt    *time.Time  `json:"deleted_at,omitempty"` // Set when the character was removed upstream
+
+	OriginPlanetID *int `gorm:"index" json:"origin_planet_id,omitempty"` // Known once the detail of the character or its planet is fetched
+
+	TransformationsFetched bool `gorm:"not null;default:false" json:"-"` // Set once the transformations are cached
+
+	FetchedAt time.Time `json:"fetched_at"` // Last time the data was fetched from the api
+	UpdatedAt time.Time `json:"updated_at"` // Last time the data changed
+
+	UnparsedKi []string `gorm:"-" json:"unparsed_ki,omitempty"` // Raw ki values that could not be parsed
 }
 
 func (c *Character) IsValid() bool {
 	return c.ID != 0 && c.Name != ""
 }
+
+// IsStale reports whether the cached character is older than the ttl.
+// A zero ttl never expires.
+func (c *Character) IsStale(ttl time.Duration) bool {
+	return ttl > 0 && time.Since(c.FetchedAt) > ttl
+}
+
+// SameData reports whether the fetched character has the data already saved.
+// A missing origin planet is not a change, the known one is kept when saving.
+func (c *Character) SameData(fetched *Character) bool {
+	if fetched.OriginPlanetID != nil && (c.OriginPlanetID == nil || *c.OriginPlanetID != *fetched.OriginPlanetID) {
+		return false
+	}
+	return c.Name == fetched.Name &&
+		c.Ki == fetched.Ki &&
+		c.KiNumeric.Equal(fetched.KiNumeric) &&
+		c.MaxKi == fetched.MaxKi &&
+		c.MaxKiNumeric.Equal(fetched.MaxKiNumeric) &&
+		c.Race == fetched.Race &&
+		c.Gender == fetched.Gender &&
+		c.Description == fetched.Description &&
+		c.Image == fetched.Image &&
+		c.Affiliation == fetched.Affiliation &&
+		SameTime(c.DeletedAt, fetched.DeletedAt)
+}
+
+// SameTime compares optional timestamps with the microsecond precision of the database
+func SameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Truncate(time.Microsecond).Equal(b.Truncate(time.Microsecond))
+}
+
+// ParseKi fills the numeric power levels from the raw ki strings.
+// Values that cannot be parsed are left as nil and reported in UnparsedKi.
+func (c *Character) ParseKi() []error {
+	var errs []error
+
+	parse := func(raw string) *PowerLevel {
+		if raw == "" {
+			return nil
+		}
+		value, err := ParseKi(raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("character %d: %w", c.ID, err))
+			return nil
+		}
+		return NewPowerLevel(value)
+	}
+
+	c.KiNumeric = parse(c.Ki)
+	c.MaxKiNumeric = parse(c.MaxKi)
+	c.reportUnparsedKi()
+
+	return errs
+}
+
+// AfterFind leaves the NULL power levels as nil and reports the unparsed
+// values of the characters read from the database
+func (c *Character) AfterFind(tx *gorm.DB) error {
+	c.KiNumeric = nullable(c.KiNumeric)
+	c.MaxKiNumeric = nullable(c.MaxKiNumeric)
+	c.reportUnparsedKi()
+	return nil
+}
+
+func (c *Character) reportUnparsedKi() {
+	c.UnparsedKi = nil
+	if c.Ki != "" && c.KiNumeric == nil {
+		c.UnparsedKi = append(c.UnparsedKi, c.Ki)
+	}
+	if c.MaxKi != "" && c.MaxKiNumeric == nil {
+		c.UnparsedKi = append(c.UnparsedKi, c.MaxKi)
+	}
+}
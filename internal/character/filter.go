@@ -0,0 +1,76 @@
+package character
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidSort   = errors.New("invalid sort field")
+	ErrInvalidFilter = errors.New("invalid filter")
+)
+
+// sortColumns whitelists the fields the listing can be sorted by
+var sortColumns = map[string]string{
+	"id":     "id",
+	"name":   "name",
+	"race":   "race",
+	"ki":     "ki_numeric",
+	"max_ki": "max_ki_numeric",
+}
+
+// ListFilter narrows the listed characters. Empty fields are ignored.
+type ListFilter struct {
+	Race        string
+	Affiliation string
+	Gender      string
+	MinKi       *PowerLevel
+	MaxKi       *PowerLevel
+}
+
+// SortOrder is a whitelisted field to sort the listing by
+type SortOrder struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSortOrder converts the value of the sort query param, e.g. "name" or "-ki".
+// An empty value returns the zero SortOrder, which sorts by id.
+func ParseSortOrder(value string) (SortOrder, error) {
+	if value == "" {
+		return SortOrder{}, nil
+	}
+
+	order := SortOrder{Field: strings.ToLower(value)}
+	if strings.HasPrefix(order.Field, "-") {
+		order.Field = order.Field[1:]
+		order.Desc = true
+	}
+
+	if _, ok := sortColumns[order.Field]; !ok {
+		return SortOrder{}, fmt.Errorf("%w: %q", ErrInvalidSort, value)
+	}
+	return order, nil
+}
+
+// Column returns the database column for the field
+func (o SortOrder) Column() string {
+	if column, ok := sortColumns[o.Field]; ok {
+		return column
+	}
+	return "id"
+}
+
+// ParseKiFilter converts a ki bound from the query, accepting the same
+// formats as the api, e.g. "60.000.000" or "3 Billion"
+func ParseKiFilter(value string) (*PowerLevel, error) {
+	if value == "" {
+		return nil, nil
+	}
+	ki, err := ParseKi(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+	}
+	return NewPowerLevel(ki), nil
+}
@@ -2,10 +2,26 @@ package character
 
 import (
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/problem"
 )
 
+// problems renders the errors of the service. The messages of upstream and
+// database errors, like internal URLs or SQL, are never exposed.
+var problems = problem.NewRenderer(append([]problem.Mapping{
+	{Err: ErrCharacterNotFound, Status: http.StatusNotFound, Code: "character_not_found", Title: "Character not found"},
+	{Err: ErrNameEmpty, Status: http.StatusBadRequest, Code: "name_empty", Title: "Character name cannot be empty"},
+	{Err: ErrInvalidMatchMode, Status: http.StatusBadRequest, Code: "invalid_match_mode", Title: "Invalid match mode", Expose: true},
+	{Err: ErrInvalidSort, Status: http.StatusBadRequest, Code: "invalid_sort", Title: "Invalid sort field", Expose: true},
+	{Err: ErrInvalidFilter, Status: http.StatusBadRequest, Code: "invalid_filter", Title: "Invalid filter", Expose: true},
+	{Err: ErrInvalidCharacter, Status: http.StatusUnprocessableEntity, Code: "invalid_character", Title: "Invalid character data"},
+	{Err: ErrDatabase, Status: http.StatusInternalServerError, Code: "database_error", Title: "Database error"},
+}, problem.Upstream...)...)
+
 type Handler struct {
 	service Service
 }
@@ -16,36 +32,83 @@ func NewHandler(service Service) *Handler {
 
 func (h *Handler) RegisterRoutes(r *gin.Engine) {
 	group := r.Group("/characters")
+	group.GET("/search", h.Search)   // GET /characters/search?name=
+	group.GET("/id/:id", h.GetByID)  // GET /characters/id/:id
 	group.GET("/:name", h.GetByName) // GET /characters/:name
 	group.GET("", h.GetAll)          // GET /characters
+
+	// Gin does not allow different wildcards in the same position,
+	// so the id shares the name of the /:name wildcard
+	group.GET("/:name/transformations", h.GetTransformations) // GET /characters/:id/transformations
+}
+
+// RegisterAdminRoutes registers the maintenance routes in a protected group
+func (h *Handler) RegisterAdminRoutes(r gin.IRouter) {
+	group := r.Group("/characters")
+	group.DELETE("/misses", h.PurgeMisses)       // DELETE /admin/characters/misses
+	group.DELETE("/misses/:name", h.PurgeMisses) // DELETE /admin/characters/misses/:name
 }
 
 type getByNameRequest struct {
 	Name string `uri:"name" binding:"required"`
 }
 
-// GetByName handles GET /characters/:name
+type getByNameQuery struct {
+	Match   string `form:"match"` // Validated by ParseMatchMode
+	Refresh bool   `form:"refresh"`
+}
+
+// GetByName handles GET /characters/:name?match=exact|prefix|contains&refresh=true
 func (h *Handler) GetByName(c *gin.Context) {
 	var req getByNameRequest
 
 	// Bind the request parameters
 	if err := c.ShouldBindUri(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		problem.BadRequest(c, "Invalid request parameters")
+		return
+	}
+
+	var query getByNameQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		problem.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	mode, err := ParseMatchMode(query.Match)
+	if err != nil {
+		problems.Error(c, err)
 		return
 	}
 
 	// Use the service to get character by name
-	char, err := h.service.GetByName(req.Name)
+	char, err := h.service.GetByName(c.Request.Context(), req.Name, LookupOptions{Match: mode, Refresh: query.Refresh})
+	if err != nil {
+		problems.Error(c, err)
+		return
+	}
 
+	// Return the character
+	c.JSON(http.StatusOK, char)
+}
+
+type getByIDRequest struct {
+	ID int `uri:"id" binding:"required,min=1"`
+}
+
+// GetByID handles GET /characters/id/:id
+func (h *Handler) GetByID(c *gin.Context) {
+	var req getByIDRequest
+
+	// Bind the request parameters
+	if err := c.ShouldBindUri(&req); err != nil {
+		problem.BadRequest(c, "Invalid request parameters")
+		return
+	}
+
+	// Use the service to get character by id
+	char, err := h.service.GetByID(c.Request.Context(), req.ID)
 	if err != nil {
-		switch err {
-		case ErrCharacterNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-		case ErrInvalidCharacter:
-			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		problems.Error(c, err)
 		return
 	}
 
@@ -53,15 +116,149 @@ func (h *Handler) GetByName(c *gin.Context) {
 	c.JSON(http.StatusOK, char)
 }
 
-// List all characters saved in the database
-func (h *Handler) GetAll(c *gin.Context) {
-	// Use the service to get all characters
-	characters, err := h.service.GetAll()
+type searchRequest struct {
+	Name string `form:"name" binding:"required"`
+}
+
+// Search handles GET /characters/search?name=
+func (h *Handler) Search(c *gin.Context) {
+	var req searchRequest
+
+	// Bind the query parameters
+	if err := c.ShouldBindQuery(&req); err != nil {
+		problem.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	// Use the service to search every match
+	characters, err := h.service.Search(c.Request.Context(), req.Name)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve characters"})
+		problems.Error(c, err)
 		return
 	}
 
-	// Return the list of characters
+	// Return the matches
 	c.JSON(http.StatusOK, characters)
 }
+
+type characterIDRequest struct {
+	ID int `uri:"name" binding:"required,min=1"`
+}
+
+// GetTransformations handles GET /characters/:id/transformations
+func (h *Handler) GetTransformations(c *gin.Context) {
+	var req characterIDRequest
+
+	// Bind the request parameters
+	if err := c.ShouldBindUri(&req); err != nil {
+		problem.BadRequest(c, "Invalid request parameters")
+		return
+	}
+
+	// Use the service to get the transformations
+	transformations, err := h.service.GetTransformations(c.Request.Context(), req.ID)
+	if err != nil {
+		problems.Error(c, err)
+		return
+	}
+
+	// Return the transformations
+	c.JSON(http.StatusOK, transformations)
+}
+
+type getAllRequest struct {
+	Page        int    `form:"page" binding:"omitempty,min=1"`
+	Limit       int    `form:"limit" binding:"omitempty,min=1"`
+	Race        string `form:"race"`
+	Affiliation string `form:"affiliation"`
+	Gender      string `form:"gender"`
+	MinKi       string `form:"min_ki"`
+	MaxKi       string `form:"max_ki"`
+	Sort        string `form:"sort"`
+}
+
+// listParams validates the filters and the sort order of the request
+func (r getAllRequest) listParams() (ListParams, error) {
+	sort, err := ParseSortOrder(r.Sort)
+	if err != nil {
+		return ListParams{}, err
+	}
+	minKi, err := ParseKiFilter(r.MinKi)
+	if err != nil {
+		return ListParams{}, err
+	}
+	maxKi, err := ParseKiFilter(r.MaxKi)
+	if err != nil {
+		return ListParams{}, err
+	}
+
+	return ListParams{
+		Page:  r.Page,
+		Limit: r.Limit,
+		Filter: ListFilter{
+			Race:        r.Race,
+			Affiliation: r.Affiliation,
+			Gender:      r.Gender,
+			MinKi:       minKi,
+			MaxKi:       maxKi,
+		},
+		Sort: sort,
+	}, nil
+}
+
+// List the characters saved in the database, one page at a time.
+// Supports filtering by race, affiliation, gender and ki range, and sorting.
+func (h *Handler) GetAll(c *gin.Context) {
+	var req getAllRequest
+
+	// Bind the query parameters
+	if err := c.ShouldBindQuery(&req); err != nil {
+		problem.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	params, err := req.listParams()
+	if err != nil {
+		problems.Error(c, err)
+		return
+	}
+
+	// Use the service to get the requested page
+	page, err := h.service.GetAll(c.Request.Context(), params)
+	if err != nil {
+		problems.Error(c, err)
+		return
+	}
+
+	if page.HasNext() {
+		page.Next = pageLink(c.Request.URL, page.Page+1, page.Limit)
+	}
+	if page.HasPrev() {
+		page.Prev = pageLink(c.Request.URL, page.Page-1, page.Limit)
+	}
+
+	// Return the page of characters
+	c.JSON(http.StatusOK, page)
+}
+
+// pageLink builds the link to another page keeping the rest of the query
+func pageLink(u *url.URL, page, limit int) string {
+	query := u.Query()
+	query.Set("page", strconv.Itoa(page))
+	query.Set("limit", strconv.Itoa(limit))
+
+	link := url.URL{Path: u.Path, RawQuery: query.Encode()}
+	return link.String()
+}
+
+// PurgeMisses handles DELETE /admin/characters/misses and /admin/characters/misses/:name.
+// It forgets the names the api did not know, all of them or the one in the path.
+func (h *Handler) PurgeMisses(c *gin.Context) {
+	purged, err := h.service.PurgeMisses(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		problems.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"purged": purged})
+}
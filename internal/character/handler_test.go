@@ -1,17 +1,23 @@
 package character_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 
 	"github.com/gclamigueiro/dragon-ball-api/internal/character"
 	"github.com/gclamigueiro/dragon-ball-api/internal/character/mocks"
+	"github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
+	mock_dragonball "github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball/mocks"
+	"github.com/gclamigueiro/dragon-ball-api/internal/problem"
 )
 
 func setupRouter(handler *character.Handler) *gin.Engine {
@@ -27,7 +33,7 @@ func TestGetByName_Success(t *testing.T) {
 	router := setupRouter(handler)
 
 	expectedChar := &character.Character{Name: "Goku"}
-	mockService.On("GetByName", "goku").Return(expectedChar, nil)
+	mockService.On("GetByName", mock.Anything, "goku", character.LookupOptions{Match: character.MatchPrefix}).Return(expectedChar, nil)
 
 	req, _ := http.NewRequest(http.MethodGet, "/characters/goku", nil)
 	req.Header.Set("Content-Type", "application/json")
@@ -48,7 +54,7 @@ func TestGetByName_NotFound(t *testing.T) {
 	handler := character.NewHandler(mockService)
 	router := setupRouter(handler)
 
-	mockService.On("GetByName", "Vegeta").Return(nil, character.ErrCharacterNotFound)
+	mockService.On("GetByName", mock.Anything, "Vegeta", character.LookupOptions{Match: character.MatchPrefix}).Return(nil, character.ErrCharacterNotFound)
 
 	req, _ := http.NewRequest(http.MethodGet, "/characters/Vegeta", nil)
 	req.Header.Set("Content-Type", "application/json")
@@ -59,7 +65,11 @@ func TestGetByName_NotFound(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Code)
 	var resp map[string]interface{}
 	_ = json.Unmarshal(w.Body.Bytes(), &resp)
-	assert.Equal(t, character.ErrCharacterNotFound.Error(), resp["error"])
+	assert.Equal(t, "character_not_found", resp["code"])
+	assert.Equal(t, "/problems/character_not_found", resp["type"])
+	assert.Equal(t, float64(http.StatusNotFound), resp["status"])
+	assert.Equal(t, "/characters/Vegeta", resp["instance"])
+	assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
 	mockService.AssertExpectations(t)
 }
 
@@ -68,7 +78,8 @@ func TestGetByName_InternalError(t *testing.T) {
 	handler := character.NewHandler(mockService)
 	router := setupRouter(handler)
 
-	mockService.On("GetByName", "piccolo").Return(nil, errors.New("some internal error"))
+	dbErr := fmt.Errorf("%w: pq: relation \"characters\" does not exist", character.ErrDatabase)
+	mockService.On("GetByName", mock.Anything, "piccolo", character.LookupOptions{Match: character.MatchPrefix}).Return(nil, dbErr)
 
 	req, _ := http.NewRequest(http.MethodGet, "/characters/piccolo", nil)
 	req.Header.Set("Content-Type", "application/json")
@@ -79,22 +90,229 @@ func TestGetByName_InternalError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 	var resp map[string]interface{}
 	_ = json.Unmarshal(w.Body.Bytes(), &resp)
-	assert.Equal(t, "some internal error", resp["error"])
+	assert.Equal(t, "database_error", resp["code"])
+	assert.NotContains(t, w.Body.String(), "relation")
 	mockService.AssertExpectations(t)
 }
 
-func TestGetAll_Success(t *testing.T) {
+func TestGetByName_PropagatesRequestContext(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := character.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+	isRequestContext := mock.MatchedBy(func(c context.Context) bool {
+		return c.Value(ctxKey{}) == "request"
+	})
+	mockService.On("GetByName", isRequestContext, "Goku", mock.Anything).Return(&character.Character{Name: "Goku"}, nil)
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/characters/Goku", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetByName_UpstreamUnavailable(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := character.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	mockService.On("GetByName", mock.Anything, "Broly", mock.Anything).Return(nil, dragonball.WrapError(dragonball.ErrCircuitOpen))
+
+	req, _ := http.NewRequest(http.MethodGet, "/characters/Broly", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var resp map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, "upstream_unavailable", resp["code"])
+	mockService.AssertExpectations(t)
+}
+
+func TestGetByName_ClientCanceled(t *testing.T) {
 	mockService := new(mocks.Service)
 	handler := character.NewHandler(mockService)
 	router := setupRouter(handler)
 
-	expectedChars := []*character.Character{
-		{Name: "Goku"},
-		{Name: "Vegeta"},
+	// The client went away while the search waited for the api
+	mockService.On("GetByName", mock.Anything, "Broly", mock.Anything).Return(nil, dragonball.WrapError(context.Canceled))
+
+	req, _ := http.NewRequest(http.MethodGet, "/characters/Broly", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, problem.StatusClientClosedRequest, w.Code)
+	var resp map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, problem.CodeCanceled, resp["code"])
+	mockService.AssertExpectations(t)
+}
+
+func TestGetByName_UpstreamErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"rate limited", &dragonball.APIError{StatusCode: http.StatusTooManyRequests, URL: "http://internal-api/characters"}, http.StatusTooManyRequests},
+		{"bad gateway", &dragonball.APIError{StatusCode: http.StatusInternalServerError, URL: "http://internal-api/characters"}, http.StatusBadGateway},
+		{"gateway timeout", fmt.Errorf("%w: Get \"http://internal-api/characters\": context deadline exceeded", dragonball.ErrTimeout), http.StatusGatewayTimeout},
+		{"unavailable", dragonball.ErrCircuitOpen, http.StatusServiceUnavailable},
 	}
-	mockService.On("GetAll").Return(expectedChars, nil)
 
-	req, _ := http.NewRequest(http.MethodGet, "/characters", nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.Service)
+			handler := character.NewHandler(mockService)
+			router := setupRouter(handler)
+
+			mockService.On("GetByName", mock.Anything, "Broly", mock.Anything).Return(nil, dragonball.WrapError(tt.err))
+
+			req, _ := http.NewRequest(http.MethodGet, "/characters/Broly", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.NotContains(t, w.Body.String(), "internal-api")
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetByName_MatchMode(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := character.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	mockService.On("GetByName", mock.Anything, "Gohan", character.LookupOptions{Match: character.MatchExact}).Return(&character.Character{Name: "Gohan"}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/characters/Gohan?match=exact", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetByName_Refresh(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := character.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	opts := character.LookupOptions{Match: character.MatchPrefix, Refresh: true}
+	mockService.On("GetByName", mock.Anything, "Goku", opts).Return(&character.Character{Name: "Goku"}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/characters/Goku?refresh=true", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetByName_InvalidMatchMode(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := character.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	req, _ := http.NewRequest(http.MethodGet, "/characters/Gohan?match=fuzzy", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var resp map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, "invalid_match_mode", resp["code"])
+	assert.Equal(t, "/problems/invalid_match_mode", resp["type"])
+	mockService.AssertExpectations(t)
+}
+
+func TestGetByName_MatchModeIsCaseInsensitive(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := character.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	mockService.On("GetByName", mock.Anything, "Gohan", character.LookupOptions{Match: character.MatchExact}).Return(&character.Character{Name: "Gohan"}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/characters/Gohan?match=EXACT", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetByID_Success(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := character.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	mockService.On("GetByID", mock.Anything, 1).Return(&character.Character{ID: 1, Name: "Goku"}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/characters/id/1", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp character.Character
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, "Goku", resp.Name)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetByID_NotFound(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := character.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	mockService.On("GetByID", mock.Anything, 999).Return(nil, character.ErrCharacterNotFound)
+
+	req, _ := http.NewRequest(http.MethodGet, "/characters/id/999", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetByID_InvalidID(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := character.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	req, _ := http.NewRequest(http.MethodGet, "/characters/id/goku", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestSearch_Success(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := character.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	expectedChars := []*character.Character{{Name: "Gohan"}, {Name: "Goku"}}
+	mockService.On("Search", mock.Anything, "Go").Return(expectedChars, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/characters/search?name=Go", nil)
 	w := httptest.NewRecorder()
 
 	router.ServeHTTP(w, req)
@@ -104,8 +322,230 @@ func TestGetAll_Success(t *testing.T) {
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
 	assert.Len(t, resp, 2)
-	assert.Equal(t, "Goku", resp[0].Name)
-	assert.Equal(t, "Vegeta", resp[1].Name)
+	mockService.AssertExpectations(t)
+}
+
+func TestSearch_MissingName(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := character.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	req, _ := http.NewRequest(http.MethodGet, "/characters/search", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestSearch_NotFound(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := character.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	mockService.On("Search", mock.Anything, "Zzz").Return(nil, character.ErrCharacterNotFound)
+
+	req, _ := http.NewRequest(http.MethodGet, "/characters/search?name=Zzz", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTransformations_Success(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := character.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	expected := []*character.Transformation{{ID: 1, CharacterID: 1, Name: "Goku SSJ"}}
+	mockService.On("GetTransformations", mock.Anything, 1).Return(expected, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/characters/1/transformations", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp []character.Transformation
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Len(t, resp, 1)
+	assert.Equal(t, "Goku SSJ", resp[0].Name)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTransformations_InvalidID(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := character.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	req, _ := http.NewRequest(http.MethodGet, "/characters/goku/transformations", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTransformations_NotFound(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := character.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	mockService.On("GetTransformations", mock.Anything, 999).Return(nil, character.ErrCharacterNotFound)
+
+	req, _ := http.NewRequest(http.MethodGet, "/characters/999/transformations", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAll_Success(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := character.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	expectedPage := &character.Page{
+		Items: []*character.Character{
+			{Name: "Goku"},
+			{Name: "Vegeta"},
+		},
+		Total: 2,
+		Page:  1,
+		Limit: 20,
+	}
+	mockService.On("GetAll", mock.Anything, character.ListParams{}).Return(expectedPage, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/characters", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp character.Page
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Items, 2)
+	assert.Equal(t, "Goku", resp.Items[0].Name)
+	assert.Equal(t, "Vegeta", resp.Items[1].Name)
+	assert.Equal(t, int64(2), resp.Total)
+	assert.Empty(t, resp.Next)
+	assert.Empty(t, resp.Prev)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAll_Pagination(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := character.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	expectedPage := &character.Page{
+		Items: []*character.Character{{Name: "Gohan"}},
+		Total: 5,
+		Page:  2,
+		Limit: 1,
+	}
+	mockService.On("GetAll", mock.Anything, character.ListParams{Page: 2, Limit: 1}).Return(expectedPage, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/characters?page=2&limit=1", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp character.Page
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, "/characters?limit=1&page=3", resp.Next)
+	assert.Equal(t, "/characters?limit=1&page=1", resp.Prev)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAll_FiltersAndSort(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := character.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	minKi, _ := character.ParseKiFilter("3 Billion")
+	expectedParams := character.ListParams{
+		Filter: character.ListFilter{
+			Race:        "Saiyan",
+			Affiliation: "Z Fighter",
+			MinKi:       minKi,
+		},
+		Sort: character.SortOrder{Field: "ki", Desc: true},
+	}
+	expectedPage := &character.Page{
+		Items: []*character.Character{{Name: "Goku"}},
+		Total: 2,
+		Page:  1,
+		Limit: 1,
+	}
+	mockService.On("GetAll", mock.Anything, expectedParams).Return(expectedPage, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/characters?race=Saiyan&affiliation=Z+Fighter&min_ki=3+Billion&sort=-ki", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp character.Page
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, "/characters?affiliation=Z+Fighter&limit=1&min_ki=3+Billion&page=2&race=Saiyan&sort=-ki", resp.Next)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAll_InvalidSort(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := character.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	req, _ := http.NewRequest(http.MethodGet, "/characters?sort=password", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var resp map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, "invalid_sort", resp["code"])
+	assert.Equal(t, `invalid sort field: "password"`, resp["detail"])
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAll_InvalidKiFilter(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := character.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	req, _ := http.NewRequest(http.MethodGet, "/characters?max_ki=over+9000+apples", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAll_InvalidQuery(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := character.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	req, _ := http.NewRequest(http.MethodGet, "/characters?page=-1", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
 	mockService.AssertExpectations(t)
 }
 
@@ -114,7 +554,7 @@ func TestGetAll_InternalError(t *testing.T) {
 	handler := character.NewHandler(mockService)
 	router := setupRouter(handler)
 
-	mockService.On("GetAll").Return(nil, errors.New("db error"))
+	mockService.On("GetAll", mock.Anything, character.ListParams{}).Return(nil, errors.New("db error"))
 
 	req, _ := http.NewRequest(http.MethodGet, "/characters", nil)
 	w := httptest.NewRecorder()
@@ -124,6 +564,52 @@ func TestGetAll_InternalError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 	var resp map[string]interface{}
 	_ = json.Unmarshal(w.Body.Bytes(), &resp)
-	assert.Equal(t, "Failed to retrieve characters", resp["error"])
+	assert.Equal(t, "internal_error", resp["code"])
+	assert.NotContains(t, w.Body.String(), "db error")
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAll_DatabaseError(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	handler := character.NewHandler(character.NewService(new(mock_dragonball.Client), mockRepo))
+	router := setupRouter(handler)
+
+	mockRepo.On("FindAll", mock.Anything, mock.Anything).Return(nil, int64(0), errors.New("pq: connection refused"))
+
+	req, _ := http.NewRequest(http.MethodGet, "/characters", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var resp map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, "database_error", resp["code"])
+	assert.Equal(t, "/problems/database_error", resp["type"])
+	assert.NotContains(t, w.Body.String(), "connection refused")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPurgeMisses(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := character.NewHandler(mockService)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler.RegisterAdminRoutes(router.Group("/admin"))
+
+	mockService.On("PurgeMisses", mock.Anything, "").Return(int64(3), nil)
+	mockService.On("PurgeMisses", mock.Anything, "Gokku").Return(int64(1), nil)
+
+	for path, purged := range map[string]float64{"/admin/characters/misses": 3, "/admin/characters/misses/Gokku": 1} {
+		req, _ := http.NewRequest(http.MethodDelete, path, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, purged, resp["purged"])
+	}
 	mockService.AssertExpectations(t)
 }
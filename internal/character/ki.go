@@ -0,0 +1,223 @@
+package character
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var ErrUnparsableKi = errors.New("unparsable ki value")
+
+// kiUnits maps the scale words used by the api to their power of ten
+var kiUnits = map[string]int64{
+	"thousand":     3,
+	"million":      6,
+	"billion":      9,
+	"trillion":     12,
+	"quadrillion":  15,
+	"quintillion":  18,
+	"sextillion":   21,
+	"septillion":   24,
+	"octillion":    27,
+	"nonillion":    30,
+	"decillion":    33,
+	"undecillion":  36,
+	"duodecillion": 39,
+	"googol":       100,
+}
+
+// ParseKi converts the ki values returned by the api into a number.
+// Values look like "60.000.000", "2.5 Billion" or "90 Septillion": without a
+// scale word dots and commas are thousand separators, with one they can
+// also be the decimal separator. Decimals left after scaling are truncated.
+func ParseKi(raw string) (*big.Int, error) {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	if value == "" {
+		return nil, fmt.Errorf("%w: empty value", ErrUnparsableKi)
+	}
+
+	fields := strings.Fields(value)
+	number := strings.Join(fields, "")
+	var exp int64
+
+	// The scale word is always the last one, e.g. "90 Septillion"
+	if len(fields) > 1 {
+		unit := fields[len(fields)-1]
+		scale, ok := kiUnits[unit]
+		if !ok {
+			scale, ok = kiUnits[strings.TrimSuffix(unit, "s")]
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown scale %q in %q", ErrUnparsableKi, unit, raw)
+		}
+		exp = scale
+		number = strings.Join(fields[:len(fields)-1], "")
+	}
+
+	number, ok := normalizeKiNumber(number, exp > 0)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnparsableKi, raw)
+	}
+
+	rat, ok := new(big.Rat).SetString(number)
+	if !ok || rat.Sign() < 0 {
+		return nil, fmt.Errorf("%w: %q", ErrUnparsableKi, raw)
+	}
+
+	rat.Mul(rat, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(exp), nil)))
+
+	return new(big.Int).Quo(rat.Num(), rat.Denom()), nil
+}
+
+// normalizeKiNumber removes the thousand separators and leaves a dot as the
+// only decimal separator. It returns false if the value is not a number.
+func normalizeKiNumber(number string, scaled bool) (string, bool) {
+	if number == "" {
+		return "", false
+	}
+	for _, r := range number {
+		if (r < '0' || r > '9') && r != '.' && r != ',' {
+			return "", false
+		}
+	}
+
+	dots, commas := strings.Count(number, "."), strings.Count(number, ",")
+	switch {
+	case dots > 0 && commas > 0:
+		// "1,234.5" or "1.234,5": the last one is the decimal separator
+		thousands, decimal := ",", "."
+		if strings.LastIndex(number, ",") > strings.LastIndex(number, ".") {
+			thousands, decimal = ".", ","
+		}
+		i := strings.LastIndex(number, decimal)
+		integer, fraction := number[:i], number[i+1:]
+		if strings.Contains(integer, decimal) || !thousandGroups(integer, thousands) {
+			return "", false
+		}
+		return strings.ReplaceAll(integer, thousands, "") + "." + fraction, true
+	case dots > 1:
+		return strings.ReplaceAll(number, ".", ""), thousandGroups(number, ".")
+	case commas > 1:
+		return strings.ReplaceAll(number, ",", ""), thousandGroups(number, ",")
+	case dots == 1, commas == 1:
+		sep := "."
+		if commas == 1 {
+			sep = ","
+		}
+		// "60.000" is sixty thousand, but "19.84 Septillion" or "2.5" are decimals
+		if !scaled && thousandGroups(number, sep) {
+			return strings.ReplaceAll(number, sep, ""), true
+		}
+		return strings.Replace(number, sep, ".", 1), true
+	default:
+		return number, true
+	}
+}
+
+// thousandGroups reports whether every group after the first one has three digits
+func thousandGroups(number, sep string) bool {
+	groups := strings.Split(number, sep)
+	if groups[0] == "" || len(groups[0]) > 3 {
+		return false
+	}
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return false
+		}
+	}
+	return true
+}
+
+// PowerLevel is an arbitrary-precision ki value. It is stored as a NUMERIC
+// column and rendered as a JSON string so clients do not lose precision.
+type PowerLevel struct {
+	big.Int
+
+	// null is set when the column was NULL, AfterFind turns it into a nil pointer
+	null bool
+}
+
+// NewPowerLevel wraps the value, returning nil for a nil value
+func NewPowerLevel(value *big.Int) *PowerLevel {
+	if value == nil {
+		return nil
+	}
+	p := &PowerLevel{}
+	p.Set(value)
+	return p
+}
+
+// Equal reports whether both values are the same, two nil values are equal
+func (p *PowerLevel) Equal(other *PowerLevel) bool {
+	p, other = nullable(p), nullable(other)
+	if p == nil || other == nil {
+		return p == other
+	}
+	return p.Cmp(&other.Int) == 0
+}
+
+// Value implements driver.Valuer
+func (p *PowerLevel) Value() (driver.Value, error) {
+	if nullable(p) == nil {
+		return nil, nil
+	}
+	return p.String(), nil
+}
+
+// Scan implements sql.Scanner. The value is NULL when the ki could not be
+// parsed, and for the rows saved before the numeric columns existed.
+func (p *PowerLevel) Scan(src interface{}) error {
+	p.null = false
+
+	var value string
+	switch v := src.(type) {
+	case nil:
+		p.SetInt64(0)
+		p.null = true
+		return nil
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	case int64:
+		p.SetInt64(v)
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into PowerLevel", src)
+	}
+
+	// NUMERIC columns can come back as "60000000.0"
+	value, _, _ = strings.Cut(value, ".")
+	if _, ok := p.SetString(value, 10); !ok {
+		return fmt.Errorf("cannot scan %q into PowerLevel", value)
+	}
+	return nil
+}
+
+// nullable returns nil if the value was scanned from a NULL column
+func nullable(p *PowerLevel) *PowerLevel {
+	if p == nil || p.null {
+		return nil
+	}
+	return p
+}
+
+// MarshalJSON renders the value as a string
+func (p *PowerLevel) MarshalJSON() ([]byte, error) {
+	if nullable(p) == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(p.String())
+}
+
+// UnmarshalJSON accepts the value as a string or as a number
+func (p *PowerLevel) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if _, ok := p.SetString(value, 10); !ok {
+		return fmt.Errorf("invalid power level %s", data)
+	}
+	return nil
+}
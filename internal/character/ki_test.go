@@ -0,0 +1,120 @@
+package character_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/character"
+	"github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
+)
+
+func TestParseKi(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected string
+	}{
+		{"60.000.000", "60000000"},
+		{"60,000,000", "60000000"},
+		{"0", "0"},
+		{"500", "500"},
+		{"60.000", "60000"},
+		{"2.5", "2"},
+		{"3 Billion", "3000000000"},
+		{"90 Septillion", "90000000000000000000000000"},
+		{"19.84 Septillion", "19840000000000000000000000"},
+		{"969 googolplex", ""},
+		{"1.5 Trillion", "1500000000000"},
+		{"11,25 Billion", "11250000000"},
+		{"1.234,5", "1234"},
+		{"1,234,567.8", "1234567"},
+		{"1,2,3.4", ""},
+		{"1.2,3", ""},
+		{"1.234,5,6", ""},
+		{"1,234.5.6", ""},
+		{"2 billions", "2000000000"},
+		{"unknown", ""},
+		{"", ""},
+		{"12 apples", ""},
+		{"1.2.3", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			value, err := character.ParseKi(tt.raw)
+			if tt.expected == "" {
+				assert.ErrorIs(t, err, character.ErrUnparsableKi)
+				assert.Nil(t, value)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, value.String())
+		})
+	}
+}
+
+func TestFromAPIResponse_ParsesKi(t *testing.T) {
+	char := character.FromAPIResponse(&dragonball.Character{
+		ID:    1,
+		Name:  "Goku",
+		Ki:    "60.000.000",
+		MaxKi: "90 Septillion",
+	})
+
+	assert.Equal(t, "60000000", char.KiNumeric.String())
+	assert.Equal(t, "90000000000000000000000000", char.MaxKiNumeric.String())
+	assert.Empty(t, char.UnparsedKi)
+
+	body, err := json.Marshal(char)
+	assert.NoError(t, err)
+	var resp map[string]interface{}
+	assert.NoError(t, json.Unmarshal(body, &resp))
+	assert.Equal(t, "60000000", resp["ki_numeric"])
+	assert.Equal(t, "90000000000000000000000000", resp["max_ki_numeric"])
+}
+
+func TestFromAPIResponse_ReportsUnparsedKi(t *testing.T) {
+	char := character.FromAPIResponse(&dragonball.Character{
+		ID:    70,
+		Name:  "Zeno",
+		Ki:    "Googolplex",
+		MaxKi: "Googolplex",
+	})
+
+	assert.Nil(t, char.KiNumeric)
+	assert.Nil(t, char.MaxKiNumeric)
+	assert.Equal(t, "Googolplex", char.Ki)
+	assert.Equal(t, []string{"Googolplex", "Googolplex"}, char.UnparsedKi)
+}
+
+func TestPowerLevel_Scan(t *testing.T) {
+	var p character.PowerLevel
+	assert.NoError(t, p.Scan("90000000000000000000000000"))
+	assert.Equal(t, "90000000000000000000000000", p.String())
+
+	assert.NoError(t, p.Scan([]byte("60000000.0")))
+	assert.Equal(t, "60000000", p.String())
+
+	assert.Error(t, p.Scan(1.5))
+}
+
+func TestPowerLevel_ScanNull(t *testing.T) {
+	var p character.PowerLevel
+	require.NoError(t, p.Scan(nil))
+
+	value, err := p.Value()
+	assert.NoError(t, err)
+	assert.Nil(t, value, "NULL should be written back as NULL")
+	assert.True(t, p.Equal(nil))
+
+	data, err := json.Marshal(&p)
+	assert.NoError(t, err)
+	assert.Equal(t, "null", string(data))
+
+	// The value is not null anymore once scanned again
+	require.NoError(t, p.Scan("60000000"))
+	assert.Equal(t, "60000000", p.String())
+	assert.False(t, p.Equal(nil))
+}
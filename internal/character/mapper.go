@@ -1,12 +1,64 @@
 package character
 
-import "github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
+)
 
 func FromAPIResponse(apiChar *dragonball.Character) *Character {
-	return &Character{
-		ID:   apiChar.ID,
-		Name: apiChar.Name,
-		Ki:   apiChar.Ki,
-		Race: apiChar.Race,
+	character := &Character{
+		ID:          apiChar.ID,
+		Name:        apiChar.Name,
+		Ki:          apiChar.Ki,
+		MaxKi:       apiChar.MaxKi,
+		Race:        apiChar.Race,
+		Gender:      apiChar.Gender,
+		Description: apiChar.Description,
+		Image:       apiChar.Image,
+		Affiliation: apiChar.Affiliation,
+		DeletedAt:   apiChar.DeletedAt,
+		FetchedAt:   time.Now(),
+	}
+
+	// Keep the raw value even if it cannot be parsed, the error is only reported
+	for _, err := range character.ParseKi() {
+		slog.Warn("failed to parse ki", "error", err)
+	}
+
+	return character
+}
+
+// FromAPIDetail maps the detail of a character, linking it to its origin planet
+func FromAPIDetail(apiDetail *dragonball.CharacterDetail) *Character {
+	character := FromAPIResponse(&apiDetail.Character)
+	if apiDetail.OriginPlanet != nil && apiDetail.OriginPlanet.ID != 0 {
+		planetID := apiDetail.OriginPlanet.ID
+		character.OriginPlanetID = &planetID
+	}
+	return character
+}
+
+func TransformationsFromAPIResponse(characterID int, apiTransformations []*dragonball.Transformation) []*Transformation {
+	transformations := make([]*Transformation, 0, len(apiTransformations))
+	for _, apiTransformation := range apiTransformations {
+		transformation := &Transformation{
+			ID:          apiTransformation.ID,
+			CharacterID: characterID,
+			Name:        apiTransformation.Name,
+			Ki:          apiTransformation.Ki,
+			Image:       apiTransformation.Image,
+			DeletedAt:   apiTransformation.DeletedAt,
+		}
+
+		if ki, err := ParseKi(apiTransformation.Ki); err == nil {
+			transformation.KiNumeric = NewPowerLevel(ki)
+		} else if apiTransformation.Ki != "" {
+			slog.Warn("failed to parse ki of transformation", "transformation_id", apiTransformation.ID, "error", err)
+		}
+
+		transformations = append(transformations, transformation)
 	}
+	return transformations
 }
@@ -0,0 +1,112 @@
+package character
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// MatchMode tells how a name is compared against the stored names
+type MatchMode string
+
+const (
+	MatchExact    MatchMode = "exact"
+	MatchPrefix   MatchMode = "prefix"
+	MatchContains MatchMode = "contains"
+)
+
+// DefaultMatchMode keeps the original behaviour of the name lookup
+const DefaultMatchMode = MatchPrefix
+
+var ErrInvalidMatchMode = errors.New("invalid match mode")
+
+// ParseMatchMode converts the value of the match query param.
+// An empty value returns the default mode.
+func ParseMatchMode(value string) (MatchMode, error) {
+	switch MatchMode(strings.ToLower(value)) {
+	case "":
+		return DefaultMatchMode, nil
+	case MatchExact:
+		return MatchExact, nil
+	case MatchPrefix:
+		return MatchPrefix, nil
+	case MatchContains:
+		return MatchContains, nil
+	default:
+		return "", ErrInvalidMatchMode
+	}
+}
+
+// Matches reports whether the name matches the search in this mode (case-insensitive)
+func (m MatchMode) Matches(search, name string) bool {
+	search = strings.ToLower(search)
+	name = strings.ToLower(name)
+
+	switch m {
+	case MatchExact:
+		return name == search
+	case MatchContains:
+		return strings.Contains(name, search)
+	default:
+		return strings.HasPrefix(name, search)
+	}
+}
+
+// pattern returns the LIKE pattern used by the repository for this mode
+func (m MatchMode) pattern(name string) string {
+	switch m {
+	case MatchExact:
+		return name
+	case MatchContains:
+		return "%" + name + "%"
+	default:
+		return name + "%"
+	}
+}
+
+// matchRank tells how close a character name is to the searched one.
+// Lower is better: exact matches first, then prefix, then contains, then anything else.
+func matchRank(search, name string) int {
+	switch {
+	case MatchExact.Matches(search, name):
+		return 0
+	case MatchPrefix.Matches(search, name):
+		return 1
+	case MatchContains.Matches(search, name):
+		return 2
+	default:
+		return 3
+	}
+}
+
+// rankByName sorts the characters so the best matches for the name come first.
+// Characters with the same rank are ordered by the shortest name in
+// characters, like LENGTH in the repository, and then by their bytes, which
+// for non-ASCII names may differ from the collation of the database.
+func rankByName(name string, characters []*Character) {
+	sort.SliceStable(characters, func(i, j int) bool {
+		a, b := characters[i], characters[j]
+		if ra, rb := matchRank(name, a.Name), matchRank(name, b.Name); ra != rb {
+			return ra < rb
+		}
+		if la, lb := utf8.RuneCountInString(a.Name), utf8.RuneCountInString(b.Name); la != lb {
+			return la < lb
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	})
+}
+
+// bestMatch returns the best ranked character matching the name in this mode
+func bestMatch(name string, mode MatchMode, characters []*Character) *Character {
+	rankByName(name, characters)
+	for _, character := range characters {
+		if mode.Matches(name, character.Name) {
+			return character
+		}
+	}
+	return nil
+}
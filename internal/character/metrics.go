@@ -0,0 +1,19 @@
+package character
+
+import "sync/atomic"
+
+// Metrics counts what the service does. It is safe for concurrent use.
+type Metrics struct {
+	// UpstreamSearches is the number of name searches sent to the api
+	UpstreamSearches atomic.Int64
+	// CoalescedCalls is the number of callers that waited for a search
+	// already in flight instead of sending their own
+	CoalescedCalls atomic.Int64
+
+	// Where GetByName answered from. A lookup is a cache miss when the api
+	// is asked, even if a stale copy is served because the api failed.
+	SharedCacheHits atomic.Int64 // Fresh in the shared cache
+	DatabaseHits    atomic.Int64 // Fresh in the database
+	NegativeHits    atomic.Int64 // Known to be unknown to the api
+	CacheMisses     atomic.Int64 // Asked the api
+}
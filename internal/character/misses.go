@@ -0,0 +1,139 @@
+package character
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/cache"
+)
+
+// missKeyPrefix is the prefix of the misses stored in a cache
+const missKeyPrefix = "character:miss:"
+
+// DefaultMaxMisses bounds the names remembered by the in-memory miss store
+const DefaultMaxMisses = 10000
+
+// MissStore remembers the names the api does not know (negative cache),
+// so they are answered with ErrCharacterNotFound without calling out
+type MissStore interface {
+	// Has reports whether the name is a known miss that has not expired
+	Has(ctx context.Context, name string) (bool, error)
+	Add(ctx context.Context, name string) error
+	Delete(ctx context.Context, name string) error
+	// Purge forgets every miss, returning how many were removed
+	Purge(ctx context.Context) (int64, error)
+}
+
+// normalizeName makes the different spellings of a name share the same key
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+type memoryMisses struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	expires    map[string]time.Time
+}
+
+// NewMemoryMissStore keeps the misses in memory for the ttl. At most
+// maxEntries names are kept, new misses are ignored while it is full.
+func NewMemoryMissStore(ttl time.Duration, maxEntries int) MissStore {
+	return &memoryMisses{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		expires:    make(map[string]time.Time),
+	}
+}
+
+func (m *memoryMisses) Has(_ context.Context, name string) (bool, error) {
+	key := normalizeName(name)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expires, ok := m.expires[key]
+	if !ok {
+		return false, nil
+	}
+	if time.Now().After(expires) {
+		delete(m.expires, key)
+		return false, nil
+	}
+	return true, nil
+}
+
+func (m *memoryMisses) Add(_ context.Context, name string) error {
+	key := normalizeName(name)
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.expires[key]; !ok && len(m.expires) >= m.maxEntries {
+		m.pruneExpired(now)
+		if len(m.expires) >= m.maxEntries {
+			return nil
+		}
+	}
+	m.expires[key] = now.Add(m.ttl)
+	return nil
+}
+
+func (m *memoryMisses) Delete(_ context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.expires, normalizeName(name))
+	return nil
+}
+
+func (m *memoryMisses) Purge(_ context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Only the misses that had not expired are reported
+	m.pruneExpired(time.Now())
+	removed := int64(len(m.expires))
+	m.expires = make(map[string]time.Time)
+	return removed, nil
+}
+
+// pruneExpired removes the expired names, the lock must be held
+func (m *memoryMisses) pruneExpired(now time.Time) {
+	for key, expires := range m.expires {
+		if now.After(expires) {
+			delete(m.expires, key)
+		}
+	}
+}
+
+type cacheMisses struct {
+	cache cache.Cache
+	ttl   time.Duration
+}
+
+// NewCacheMissStore keeps the misses in the cache for the ttl, so they are
+// shared by every replica using the same cache
+func NewCacheMissStore(c cache.Cache, ttl time.Duration) MissStore {
+	return &cacheMisses{cache: c, ttl: ttl}
+}
+
+func (m *cacheMisses) Has(ctx context.Context, name string) (bool, error) {
+	_, ok, err := m.cache.Get(ctx, missKeyPrefix+normalizeName(name))
+	return ok, err
+}
+
+func (m *cacheMisses) Add(ctx context.Context, name string) error {
+	return m.cache.Set(ctx, missKeyPrefix+normalizeName(name), []byte("1"), m.ttl)
+}
+
+func (m *cacheMisses) Delete(ctx context.Context, name string) error {
+	return m.cache.Delete(ctx, missKeyPrefix+normalizeName(name))
+}
+
+func (m *cacheMisses) Purge(ctx context.Context) (int64, error) {
+	return m.cache.DeletePrefix(ctx, missKeyPrefix)
+}
@@ -0,0 +1,104 @@
+package character
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Miss is a name the api did not know, persisted in the character_misses table
+type Miss struct {
+	Name     string    `gorm:"primaryKey"`
+	MissedAt time.Time `gorm:"not null"`
+}
+
+// TableName keeps the table name explicit, GORM would use "misses"
+func (Miss) TableName() string {
+	return "character_misses"
+}
+
+// missPruneInterval is how often a replica removes the expired misses
+const missPruneInterval = 10 * time.Minute
+
+type storageMisses struct {
+	db  *gorm.DB
+	ttl time.Duration
+
+	mu       sync.Mutex
+	prunedAt time.Time
+}
+
+// NewStorageMissStore keeps the misses in the character_misses table for
+// the ttl, so they are shared by every replica and survive restarts.
+// The expired rows are removed while new misses are added.
+func NewStorageMissStore(db *gorm.DB, ttl time.Duration) MissStore {
+	return &storageMisses{db: db, ttl: ttl}
+}
+
+func (s *storageMisses) Has(ctx context.Context, name string) (bool, error) {
+	var count int64
+	err := s.db.WithContext(ctx).Model(&Miss{}).
+		Where("name = ? AND missed_at > ?", normalizeName(name), time.Now().Add(-s.ttl)).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (s *storageMisses) Add(ctx context.Context, name string) error {
+	miss := &Miss{Name: normalizeName(name), MissedAt: time.Now()}
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"missed_at"}),
+	}).Create(miss).Error
+	if err != nil {
+		return err
+	}
+	return s.pruneExpired(ctx)
+}
+
+// pruneExpired removes the expired rows, at most once per missPruneInterval,
+// so the table does not grow with names nobody looks up again
+func (s *storageMisses) pruneExpired(ctx context.Context) error {
+	s.mu.Lock()
+	now := time.Now()
+	due := now.Sub(s.prunedAt) >= missPruneInterval
+	if due {
+		s.prunedAt = now
+	}
+	s.mu.Unlock()
+	if !due {
+		return nil
+	}
+
+	err := s.db.WithContext(ctx).Where("missed_at <= ?", now.Add(-s.ttl)).Delete(&Miss{}).Error
+	if err != nil {
+		// Retried on the next miss
+		s.mu.Lock()
+		s.prunedAt = time.Time{}
+		s.mu.Unlock()
+	}
+	return err
+}
+
+func (s *storageMisses) Delete(ctx context.Context, name string) error {
+	return s.db.WithContext(ctx).Where("name = ?", normalizeName(name)).Delete(&Miss{}).Error
+}
+
+// Purge only counts the misses that had not expired, the expired rows are
+// removed first without being reported
+func (s *storageMisses) Purge(ctx context.Context) (int64, error) {
+	var removed int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("missed_at <= ?", time.Now().Add(-s.ttl)).Delete(&Miss{}).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Where("1 = 1").Delete(&Miss{})
+		removed = result.RowsAffected
+		return result.Error
+	})
+	return removed, err
+}
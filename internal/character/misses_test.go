@@ -0,0 +1,110 @@
+package character_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/character"
+)
+
+func TestMemoryMissStore(t *testing.T) {
+	store := character.NewMemoryMissStore(time.Hour, 10)
+	ctx := context.Background()
+
+	known, err := store.Has(ctx, "Gokku")
+	assert.NoError(t, err)
+	assert.False(t, known)
+
+	assert.NoError(t, store.Add(ctx, "Gokku"))
+
+	// Names are case-insensitive
+	known, _ = store.Has(ctx, " GOKKU ")
+	assert.True(t, known)
+
+	assert.NoError(t, store.Delete(ctx, "gokku"))
+	known, _ = store.Has(ctx, "Gokku")
+	assert.False(t, known)
+}
+
+func TestMemoryMissStore_Expires(t *testing.T) {
+	store := character.NewMemoryMissStore(10*time.Millisecond, 10)
+	ctx := context.Background()
+
+	assert.NoError(t, store.Add(ctx, "Gokku"))
+	time.Sleep(20 * time.Millisecond)
+
+	known, _ := store.Has(ctx, "Gokku")
+	assert.False(t, known)
+}
+
+func TestMemoryMissStore_Bounded(t *testing.T) {
+	store := character.NewMemoryMissStore(time.Hour, 2)
+	ctx := context.Background()
+
+	assert.NoError(t, store.Add(ctx, "a"))
+	assert.NoError(t, store.Add(ctx, "b"))
+	assert.NoError(t, store.Add(ctx, "c"))
+
+	known, _ := store.Has(ctx, "c")
+	assert.False(t, known)
+	known, _ = store.Has(ctx, "a")
+	assert.True(t, known)
+}
+
+func TestMemoryMissStore_Purge(t *testing.T) {
+	store := character.NewMemoryMissStore(time.Hour, 10)
+	ctx := context.Background()
+
+	assert.NoError(t, store.Add(ctx, "a"))
+	assert.NoError(t, store.Add(ctx, "b"))
+
+	removed, err := store.Purge(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), removed)
+
+	known, _ := store.Has(ctx, "a")
+	assert.False(t, known)
+}
+
+func TestMemoryMissStore_PurgeIgnoresExpired(t *testing.T) {
+	store := character.NewMemoryMissStore(20*time.Millisecond, 10)
+	ctx := context.Background()
+
+	assert.NoError(t, store.Add(ctx, "a"))
+	time.Sleep(30 * time.Millisecond)
+
+	removed, err := store.Purge(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), removed)
+}
+
+func TestStorageMissStore_PurgeIgnoresExpired(t *testing.T) {
+	// 3 expired rows are removed first, then the 2 active ones
+	fake := &fakeDB{affected: []int64{3, 2}}
+	store := character.NewStorageMissStore(openFake(t, fake), time.Hour)
+
+	removed, err := store.Purge(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), removed)
+	require.Len(t, fake.statements, 2)
+	assert.Contains(t, fake.statements[0], "missed_at <=")
+}
+
+func TestStorageMissStore_AddPrunesExpired(t *testing.T) {
+	fake := &fakeDB{}
+	store := character.NewStorageMissStore(openFake(t, fake), time.Hour)
+	ctx := context.Background()
+
+	assert.NoError(t, store.Add(ctx, "Gokku"))
+	require.Len(t, fake.statements, 2)
+	assert.Contains(t, fake.statements[0], "INSERT")
+	assert.Contains(t, fake.statements[1], "missed_at <=")
+
+	// The expired rows are not removed again until the interval passes
+	assert.NoError(t, store.Add(ctx, "Vegetta"))
+	assert.Len(t, fake.statements, 3)
+}
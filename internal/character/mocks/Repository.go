@@ -3,8 +3,13 @@
 package mocks
 
 import (
+	context "context"
+
 	character "github.com/gclamigueiro/dragon-ball-api/internal/character"
+
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // Repository is an autogenerated mock type for the Repository type
@@ -12,29 +17,96 @@ type Repository struct {
 	mock.Mock
 }
 
-// FindAll provides a mock function with no fields
-func (_m *Repository) FindAll() ([]*character.Character, error) {
-	ret := _m.Called()
+// FindAll provides a mock function with given fields: ctx, params
+func (_m *Repository) FindAll(ctx context.Context, params character.ListParams) ([]*character.Character, int64, error) {
+	ret := _m.Called(ctx, params)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindAll")
 	}
 
 	var r0 []*character.Character
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, character.ListParams) ([]*character.Character, int64, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, character.ListParams) []*character.Character); ok {
+		r0 = rf(ctx, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*character.Character)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, character.ListParams) int64); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, character.ListParams) error); ok {
+		r2 = rf(ctx, params)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// FindByID provides a mock function with given fields: ctx, id
+func (_m *Repository) FindByID(ctx context.Context, id int) (*character.Character, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByID")
+	}
+
+	var r0 *character.Character
 	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]*character.Character, error)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*character.Character, error)); ok {
+		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func() []*character.Character); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context, int) *character.Character); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*character.Character)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByIDs provides a mock function with given fields: ctx, ids
+func (_m *Repository) FindByIDs(ctx context.Context, ids []int) ([]*character.Character, error) {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByIDs")
+	}
+
+	var r0 []*character.Character
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int) ([]*character.Character, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int) []*character.Character); ok {
+		r0 = rf(ctx, ids)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*character.Character)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context, []int) error); ok {
+		r1 = rf(ctx, ids)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -42,9 +114,9 @@ func (_m *Repository) FindAll() ([]*character.Character, error) {
 	return r0, r1
 }
 
-// FindByName provides a mock function with given fields: name
-func (_m *Repository) FindByName(name string) (*character.Character, error) {
-	ret := _m.Called(name)
+// FindByName provides a mock function with given fields: ctx, name, mode
+func (_m *Repository) FindByName(ctx context.Context, name string, mode character.MatchMode) (*character.Character, error) {
+	ret := _m.Called(ctx, name, mode)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindByName")
@@ -52,19 +124,49 @@ func (_m *Repository) FindByName(name string) (*character.Character, error) {
 
 	var r0 *character.Character
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (*character.Character, error)); ok {
-		return rf(name)
+	if rf, ok := ret.Get(0).(func(context.Context, string, character.MatchMode) (*character.Character, error)); ok {
+		return rf(ctx, name, mode)
 	}
-	if rf, ok := ret.Get(0).(func(string) *character.Character); ok {
-		r0 = rf(name)
+	if rf, ok := ret.Get(0).(func(context.Context, string, character.MatchMode) *character.Character); ok {
+		r0 = rf(ctx, name, mode)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*character.Character)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(name)
+	if rf, ok := ret.Get(1).(func(context.Context, string, character.MatchMode) error); ok {
+		r1 = rf(ctx, name, mode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindStale provides a mock function with given fields: ctx, fetchedBefore, limit
+func (_m *Repository) FindStale(ctx context.Context, fetchedBefore time.Time, limit int) ([]*character.Character, error) {
+	ret := _m.Called(ctx, fetchedBefore, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindStale")
+	}
+
+	var r0 []*character.Character
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) ([]*character.Character, error)); ok {
+		return rf(ctx, fetchedBefore, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) []*character.Character); ok {
+		r0 = rf(ctx, fetchedBefore, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*character.Character)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = rf(ctx, fetchedBefore, limit)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -72,17 +174,83 @@ func (_m *Repository) FindByName(name string) (*character.Character, error) {
 	return r0, r1
 }
 
-// Save provides a mock function with given fields: _a0
-func (_m *Repository) Save(_a0 *character.Character) error {
-	ret := _m.Called(_a0)
+// FindTransformations provides a mock function with given fields: ctx, characterID
+func (_m *Repository) FindTransformations(ctx context.Context, characterID int) ([]*character.Transformation, error) {
+	ret := _m.Called(ctx, characterID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindTransformations")
+	}
+
+	var r0 []*character.Transformation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*character.Transformation, error)); ok {
+		return rf(ctx, characterID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*character.Transformation); ok {
+		r0 = rf(ctx, characterID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*character.Transformation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, characterID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: ctx, _a1
+func (_m *Repository) Save(ctx context.Context, _a1 *character.Character) error {
+	ret := _m.Called(ctx, _a1)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Save")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*character.Character) error); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, *character.Character) error); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveAll provides a mock function with given fields: ctx, characters
+func (_m *Repository) SaveAll(ctx context.Context, characters []*character.Character) error {
+	ret := _m.Called(ctx, characters)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveAll")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*character.Character) error); ok {
+		r0 = rf(ctx, characters)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveTransformations provides a mock function with given fields: ctx, _a1, transformations
+func (_m *Repository) SaveTransformations(ctx context.Context, _a1 *character.Character, transformations []*character.Transformation) error {
+	ret := _m.Called(ctx, _a1, transformations)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveTransformations")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *character.Character, []*character.Transformation) error); ok {
+		r0 = rf(ctx, _a1, transformations)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -90,6 +258,36 @@ func (_m *Repository) Save(_a0 *character.Character) error {
 	return r0
 }
 
+// SearchByName provides a mock function with given fields: ctx, name
+func (_m *Repository) SearchByName(ctx context.Context, name string) ([]*character.Character, error) {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchByName")
+	}
+
+	var r0 []*character.Character
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*character.Character, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*character.Character); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*character.Character)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewRepository creates a new instance of Repository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewRepository(t interface {
@@ -3,7 +3,10 @@
 package mocks
 
 import (
+	context "context"
+
 	character "github.com/gclamigueiro/dragon-ball-api/internal/character"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -12,29 +15,29 @@ type Service struct {
 	mock.Mock
 }
 
-// GetAll provides a mock function with no fields
-func (_m *Service) GetAll() ([]*character.Character, error) {
-	ret := _m.Called()
+// GetAll provides a mock function with given fields: ctx, params
+func (_m *Service) GetAll(ctx context.Context, params character.ListParams) (*character.Page, error) {
+	ret := _m.Called(ctx, params)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetAll")
 	}
 
-	var r0 []*character.Character
+	var r0 *character.Page
 	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]*character.Character, error)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(context.Context, character.ListParams) (*character.Page, error)); ok {
+		return rf(ctx, params)
 	}
-	if rf, ok := ret.Get(0).(func() []*character.Character); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context, character.ListParams) *character.Page); ok {
+		r0 = rf(ctx, params)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*character.Character)
+			r0 = ret.Get(0).(*character.Page)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context, character.ListParams) error); ok {
+		r1 = rf(ctx, params)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -42,9 +45,39 @@ func (_m *Service) GetAll() ([]*character.Character, error) {
 	return r0, r1
 }
 
-// GetByName provides a mock function with given fields: name
-func (_m *Service) GetByName(name string) (*character.Character, error) {
-	ret := _m.Called(name)
+// GetByID provides a mock function with given fields: ctx, id
+func (_m *Service) GetByID(ctx context.Context, id int) (*character.Character, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *character.Character
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*character.Character, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *character.Character); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*character.Character)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByName provides a mock function with given fields: ctx, name, opts
+func (_m *Service) GetByName(ctx context.Context, name string, opts character.LookupOptions) (*character.Character, error) {
+	ret := _m.Called(ctx, name, opts)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetByName")
@@ -52,19 +85,107 @@ func (_m *Service) GetByName(name string) (*character.Character, error) {
 
 	var r0 *character.Character
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (*character.Character, error)); ok {
-		return rf(name)
+	if rf, ok := ret.Get(0).(func(context.Context, string, character.LookupOptions) (*character.Character, error)); ok {
+		return rf(ctx, name, opts)
 	}
-	if rf, ok := ret.Get(0).(func(string) *character.Character); ok {
-		r0 = rf(name)
+	if rf, ok := ret.Get(0).(func(context.Context, string, character.LookupOptions) *character.Character); ok {
+		r0 = rf(ctx, name, opts)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*character.Character)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(name)
+	if rf, ok := ret.Get(1).(func(context.Context, string, character.LookupOptions) error); ok {
+		r1 = rf(ctx, name, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTransformations provides a mock function with given fields: ctx, id
+func (_m *Service) GetTransformations(ctx context.Context, id int) ([]*character.Transformation, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTransformations")
+	}
+
+	var r0 []*character.Transformation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*character.Transformation, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*character.Transformation); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*character.Transformation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeMisses provides a mock function with given fields: ctx, name
+func (_m *Service) PurgeMisses(ctx context.Context, name string) (int64, error) {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeMisses")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int64, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int64); ok {
+		r0 = rf(ctx, name)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Search provides a mock function with given fields: ctx, name
+func (_m *Service) Search(ctx context.Context, name string) ([]*character.Character, error) {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Search")
+	}
+
+	var r0 []*character.Character
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*character.Character, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*character.Character); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*character.Character)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,56 @@
+package character
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// ListParams holds the options used to list the stored characters
+type ListParams struct {
+	Page   int
+	Limit  int
+	Filter ListFilter
+	Sort   SortOrder
+}
+
+// Normalize applies the default values and bounds to the params
+func (p ListParams) Normalize() ListParams {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.Limit < 1 {
+		p.Limit = DefaultPageLimit
+	}
+	if p.Limit > MaxPageLimit {
+		p.Limit = MaxPageLimit
+	}
+	if p.Sort.Field == "" {
+		p.Sort = SortOrder{Field: "id"}
+	}
+	return p
+}
+
+// Offset returns the number of rows to skip for the current page
+func (p ListParams) Offset() int {
+	return (p.Page - 1) * p.Limit
+}
+
+// Page is the envelope returned when listing characters
+type Page struct {
+	Items []*Character `json:"items"`
+	Total int64        `json:"total"`
+	Page  int          `json:"page"`
+	Limit int          `json:"limit"`
+	Next  string       `json:"next,omitempty"`
+	Prev  string       `json:"prev,omitempty"`
+}
+
+// HasNext reports whether there are more items after the current page
+func (p *Page) HasNext() bool {
+	return int64(p.Page*p.Limit) < p.Total
+}
+
+// HasPrev reports whether there is a page before the current one
+func (p *Page) HasPrev() bool {
+	return p.Page > 1
+}
@@ -1,62 +1,310 @@
 package character
 
 import (
+	"context"
 	"errors"
+	"log/slog"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/db"
 )
 
 type Repository interface {
-	FindAll() ([]*Character, error)
-	FindByName(name string) (*Character, error)
-	Save(character *Character) error
+	FindAll(ctx context.Context, params ListParams) ([]*Character, int64, error)
+	FindByID(ctx context.Context, id int) (*Character, error)
+	FindByIDs(ctx context.Context, ids []int) ([]*Character, error)
+	FindStale(ctx context.Context, fetchedBefore time.Time, limit int) ([]*Character, error)
+	FindByName(ctx context.Context, name string, mode MatchMode) (*Character, error)
+	SearchByName(ctx context.Context, name string) ([]*Character, error)
+	Save(ctx context.Context, character *Character) error
+	SaveAll(ctx context.Context, characters []*Character) error
+	FindTransformations(ctx context.Context, characterID int) ([]*Transformation, error)
+	SaveTransformations(ctx context.Context, character *Character, transformations []*Transformation) error
 }
 
 type repository struct {
 	db *gorm.DB
 }
 
-func NewStorage(db *gorm.DB) Repository {
-	return &repository{db}
+// StorageOption configures the optional behaviour of the storage
+type StorageOption func(*repository)
+
+// WithStorageLogger logs the queries of the storage to the logger instead of
+// the one of the connection
+func WithStorageLogger(logger *slog.Logger) StorageOption {
+	return func(r *repository) {
+		r.db = db.WithLogger(r.db, logger)
+	}
+}
+
+func NewStorage(conn *gorm.DB, opts ...StorageOption) Repository {
+	r := &repository{db: conn}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func (r *repository) FindAll(ctx context.Context, params ListParams) ([]*Character, int64, error) {
+	var characters []*Character
+	var total int64
+
+	query := applyFilter(r.db.WithContext(ctx).Model(&Character{}), params.Filter)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Retrieve only the requested page, the id keeps the pages stable
+	err := applyFilter(r.db.WithContext(ctx), params.Filter).
+		Clauses(bySortOrder(params.Sort)).
+		Offset(params.Offset()).
+		Limit(params.Limit).
+		Find(&characters).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return characters, total, nil
+}
+
+func (r *repository) FindByID(ctx context.Context, id int) (*Character, error) {
+	var character Character
+
+	err := r.db.WithContext(ctx).Take(&character, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &character, nil
+}
+
+// FindByIDs returns the characters with the given ids, skipping the unknown ones
+func (r *repository) FindByIDs(ctx context.Context, ids []int) ([]*Character, error) {
+	var characters []*Character
+	if len(ids) == 0 {
+		return characters, nil
+	}
+
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Order("id").Find(&characters).Error
+	if err != nil {
+		return nil, err
+	}
+	return characters, nil
 }
 
-func (r *repository) FindAll() ([]*Character, error) {
+// FindStale returns the characters fetched before the given time, the oldest
+// first. Characters never fetched, saved before fetched_at existed, go first.
+func (r *repository) FindStale(ctx context.Context, fetchedBefore time.Time, limit int) ([]*Character, error) {
 	var characters []*Character
 
-	// Retrieve all characters from the database
-	err := r.db.Find(&characters).Error
+	err := r.db.WithContext(ctx).
+		Where("fetched_at IS NULL OR fetched_at < ?", fetchedBefore).
+		Order("fetched_at ASC NULLS FIRST, id").
+		Limit(limit).
+		Find(&characters).Error
 	if err != nil {
 		return nil, err
 	}
 	return characters, nil
 }
 
-func (r *repository) FindByName(name string) (*Character, error) {
+func (r *repository) FindByName(ctx context.Context, name string, mode MatchMode) (*Character, error) {
 	var character Character
 
-	// using %% to allow for partial matches. Similar to the api
-	// that if you send "Go", it will return "Goku", "Gohan", etc.
-	// and we will retrieve only the first match.
-	err := r.db.
-		Where("LOWER(name) LIKE LOWER(?)", name+"%").
-		First(&character).Error
+	// Partial matches are similar to the api, if you send "Go" it
+	// will return "Goku", "Gohan", etc. and we retrieve only the best one:
+	// an exact match always wins, then the shortest name, then alphabetically.
+	err := r.db.WithContext(ctx).
+		Where("LOWER(name) LIKE LOWER(?)", mode.pattern(escapeLike(name))).
+		Clauses(byMatchOrder(name)).
+		Take(&character).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
-	return &character, err
+	if err != nil {
+		return nil, err
+	}
+	return &character, nil
 }
 
-func (r *repository) Save(character *Character) error {
+func (r *repository) SearchByName(ctx context.Context, name string) ([]*Character, error) {
+	var characters []*Character
+
+	// Same partial match as FindByName, but returning every row
+	err := r.db.WithContext(ctx).
+		Where("LOWER(name) LIKE LOWER(?)", MatchPrefix.pattern(escapeLike(name))).
+		Clauses(byMatchOrder(name)).
+		Find(&characters).Error
+	if err != nil {
+		return nil, err
+	}
+	return characters, nil
+}
+
+func (r *repository) Save(ctx context.Context, character *Character) error {
 	if character == nil {
 		return errors.New("character cannot be nil")
 	}
-	// If the character already exists, do nothing
-	err := r.db.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "id"}}, // conflict target
-		DoNothing: true,                          // skip insert if exists
-	}).Create(character).Error
+	// If the character already exists, refresh its data
+	err := r.db.WithContext(ctx).Clauses(upsertCharacter()).Create(character).Error
 
 	return err
 }
+
+func (r *repository) SaveAll(ctx context.Context, characters []*Character) error {
+	if len(characters) == 0 {
+		return nil
+	}
+	// Same as Save, existing characters are refreshed
+	err := r.db.WithContext(ctx).Clauses(upsertCharacter()).Create(characters).Error
+
+	return err
+}
+
+// byMatchOrder orders the rows like rankByName: exact match first, then the
+// shortest name in characters, then by the collation of the database.
+func byMatchOrder(name string) clause.OrderBy {
+	return clause.OrderBy{
+		Expression: clause.Expr{
+			SQL:                "CASE WHEN LOWER(name) = LOWER(?) THEN 0 ELSE 1 END, LENGTH(name), name, id",
+			Vars:               []interface{}{name},
+			WithoutParentheses: true,
+		},
+	}
+}
+
+// escapeLike escapes the LIKE wildcards so they are matched literally
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func (r *repository) FindTransformations(ctx context.Context, characterID int) ([]*Transformation, error) {
+	var transformations []*Transformation
+
+	err := r.db.WithContext(ctx).
+		Where("character_id = ?", characterID).
+		Order("id").
+		Find(&transformations).Error
+	if err != nil {
+		return nil, err
+	}
+	return transformations, nil
+}
+
+// SaveTransformations stores the character with its transformations and marks
+// them as fetched, so the api is not asked again for this character.
+// Existing transformations are refreshed with the fetched data.
+func (r *repository) SaveTransformations(ctx context.Context, character *Character, transformations []*Transformation) error {
+	if character == nil {
+		return errors.New("character cannot be nil")
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The character may not be cached yet
+		err := tx.Clauses(upsertCharacter()).Create(character).Error
+		if err != nil {
+			return err
+		}
+
+		if len(transformations) > 0 {
+			err = tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "id"}},
+				DoUpdates: clause.AssignmentColumns(transformationDataColumns),
+			}).Create(transformations).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		updates := map[string]interface{}{"transformations_fetched": true}
+		if character.OriginPlanetID != nil {
+			updates["origin_planet_id"] = *character.OriginPlanetID
+		}
+
+		return tx.Model(&Character{}).
+			Where("id = ?", character.ID).
+			Updates(updates).Error
+	})
+}
+
+// characterDataColumns are the columns that come from the api
+var characterDataColumns = []string{
+	"name", "ki", "ki_numeric", "max_ki", "max_ki_numeric", "race",
+	"gender", "description", "image", "affiliation", "deleted_at",
+}
+
+// transformationDataColumns are the columns of a transformation that come from the api
+var transformationDataColumns = []string{"character_id", "name", "ki", "ki_numeric", "image", "deleted_at"}
+
+// upsertCharacter refreshes an existing character with the fetched data.
+// updated_at only moves when a column actually changed, and a known origin
+// planet is kept if the new data does not have it.
+func upsertCharacter() clause.OnConflict {
+	current := "characters." + strings.Join(characterDataColumns, ", characters.")
+	excluded := "excluded." + strings.Join(characterDataColumns, ", excluded.")
+
+	assignments := clause.AssignmentColumns(append([]string{"fetched_at"}, characterDataColumns...))
+	assignments = append(assignments,
+		clause.Assignment{
+			Column: clause.Column{Name: "origin_planet_id"},
+			Value:  clause.Expr{SQL: "COALESCE(excluded.origin_planet_id, characters.origin_planet_id)"},
+		},
+		clause.Assignment{
+			Column: clause.Column{Name: "updated_at"},
+			Value: clause.Expr{SQL: "CASE WHEN ROW(" + current + ") IS DISTINCT FROM ROW(" + excluded + ")" +
+				" THEN excluded.updated_at ELSE characters.updated_at END"},
+		},
+	)
+
+	return clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: assignments,
+	}
+}
+
+// applyFilter adds a condition for every field set in the filter
+func applyFilter(db *gorm.DB, filter ListFilter) *gorm.DB {
+	if filter.Race != "" {
+		db = db.Where("LOWER(race) = LOWER(?)", filter.Race)
+	}
+	if filter.Affiliation != "" {
+		db = db.Where("LOWER(affiliation) = LOWER(?)", filter.Affiliation)
+	}
+	if filter.Gender != "" {
+		db = db.Where("LOWER(gender) = LOWER(?)", filter.Gender)
+	}
+	if filter.MinKi != nil {
+		db = db.Where("ki_numeric >= CAST(? AS NUMERIC)", filter.MinKi)
+	}
+	if filter.MaxKi != nil {
+		db = db.Where("ki_numeric <= CAST(? AS NUMERIC)", filter.MaxKi)
+	}
+	return db
+}
+
+// bySortOrder orders by the whitelisted column and then by id so pages are stable.
+// Characters with an unknown ki are always left at the end.
+func bySortOrder(order SortOrder) clause.OrderBy {
+	column := order.Column()
+	direction := "ASC"
+	if order.Desc {
+		direction = "DESC"
+	}
+
+	sql := column + " " + direction
+	if strings.HasSuffix(column, "_numeric") {
+		sql += " NULLS LAST"
+	}
+	if column != "id" {
+		sql += ", id"
+	}
+
+	// The column comes from the sortColumns whitelist, never from the request
+	return clause.OrderBy{Expression: clause.Expr{SQL: sql, WithoutParentheses: true}}
+}
@@ -0,0 +1,109 @@
+package character_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/character"
+)
+
+// fakeDB answers every query with the same rows and every statement with
+// the next number of affected rows, to test what a real database returns
+// without running one
+type fakeDB struct {
+	columns  []string
+	rows     [][]driver.Value
+	affected []int64
+
+	statements []string // Executed statements
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return f, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return nil }
+
+func (f *fakeDB) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (f *fakeDB) Close() error                        { return nil }
+func (f *fakeDB) Begin() (driver.Tx, error)           { return f, nil }
+func (f *fakeDB) Commit() error                       { return nil }
+func (f *fakeDB) Rollback() error                     { return nil }
+
+func (f *fakeDB) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	f.statements = append(f.statements, query)
+	if len(f.affected) == 0 {
+		return driver.RowsAffected(0), nil
+	}
+	affected := f.affected[0]
+	f.affected = f.affected[1:]
+	return driver.RowsAffected(affected), nil
+}
+
+func (f *fakeDB) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{columns: f.columns, rows: f.rows}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func openFake(t *testing.T, fake *fakeDB) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(fake)}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	return db
+}
+
+func TestRepository_FindByName_NullPowerLevels(t *testing.T) {
+	// Saved before the numeric columns existed, or with an unparsable ki
+	db := openFake(t, &fakeDB{
+		columns: []string{"id", "name", "ki", "ki_numeric", "max_ki", "max_ki_numeric"},
+		rows:    [][]driver.Value{{int64(1), "Goku", "Googolplex", nil, "", nil}},
+	})
+
+	found, err := character.NewStorage(db).FindByName(context.Background(), "Goku", character.MatchExact)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Nil(t, found.KiNumeric)
+	assert.Nil(t, found.MaxKiNumeric)
+	assert.Equal(t, []string{"Googolplex"}, found.UnparsedKi)
+}
+
+func TestRepository_FindTransformations_NullPowerLevel(t *testing.T) {
+	db := openFake(t, &fakeDB{
+		columns: []string{"id", "character_id", "name", "ki", "ki_numeric"},
+		rows: [][]driver.Value{
+			{int64(1), int64(1), "Goku SSJ", "3 Billion", "3000000000"},
+			{int64(2), int64(1), "Goku SSJ2", "", nil},
+		},
+	})
+
+	transformations, err := character.NewStorage(db).FindTransformations(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, transformations, 2)
+	assert.Equal(t, "3000000000", transformations[0].KiNumeric.String())
+	assert.Nil(t, transformations[1].KiNumeric)
+}
@@ -1,12 +1,37 @@
 package character
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/cache"
 	"github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
+	"github.com/gclamigueiro/dragon-ball-api/internal/logging"
 )
 
+// tracerName identifies the spans created by this package
+const tracerName = "github.com/gclamigueiro/dragon-ball-api/internal/character"
+
+// nameKeyPrefix is the prefix of the characters stored in the shared cache by name
+const nameKeyPrefix = "character:name:"
+
+// NameCacheKey returns the key of the character stored in the shared cache
+// by name, so the writers of the database can invalidate it
+func NameCacheKey(name string) string {
+	return nameKeyPrefix + normalizeName(name)
+}
+
 var (
 	ErrCharacterNotFound = errors.New("character not found")
 	ErrNameEmpty         = errors.New("character name cannot be empty")
@@ -15,67 +40,528 @@ var (
 )
 
 type Service interface {
-	GetByName(name string) (*Character, error)
-	GetAll() ([]*Character, error)
+	GetByName(ctx context.Context, name string, opts LookupOptions) (*Character, error)
+	GetByID(ctx context.Context, id int) (*Character, error)
+	Search(ctx context.Context, name string) ([]*Character, error)
+	GetTransformations(ctx context.Context, id int) ([]*Transformation, error)
+	GetAll(ctx context.Context, params ListParams) (*Page, error)
+	// PurgeMisses forgets the name in the negative cache, or every name if it is empty
+	PurgeMisses(ctx context.Context, name string) (int64, error)
+}
+
+// LookupOptions changes how a character is looked up by name
+type LookupOptions struct {
+	Match   MatchMode
+	Refresh bool // Fetch the character from the api even if the cached one is fresh
+}
+
+// Source tells where GetByName answered from
+type Source string
+
+const (
+	SourceSharedCache   Source = "shared_cache"   // Fresh in the shared cache
+	SourceDatabase      Source = "db"             // Fresh in the database
+	SourceNegativeCache Source = "negative_cache" // Known to be unknown to the api
+	SourceUpstream      Source = "upstream"       // Asked the api
+	SourceStale         Source = "stale"          // The api failed, a stale or partial local match was served
+)
+
+// IsCacheHit reports whether the lookup was answered without asking the api
+func (s Source) IsCacheHit() bool {
+	return s == SourceSharedCache || s == SourceDatabase || s == SourceNegativeCache
 }
 
 type service struct {
 	dgzClient  dragonball.Client
 	repository Repository
+	cacheTTL   time.Duration
+	metrics    *Metrics
+	misses     MissStore
+	cache      cache.Cache
+	logger     *slog.Logger
+
+	// searches deduplicates the concurrent searches of the same name
+	searches singleflight.Group
+}
+
+// Option configures the optional behaviour of the service
+type Option func(*service)
+
+// WithCacheTTL sets how long a cached character is served before it is
+// fetched again from the api. Zero, the default, never expires them.
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(s *service) {
+		s.cacheTTL = ttl
+	}
+}
+
+// WithCache keeps the characters found by name in a cache shared with the
+// other replicas, so the ones fetched by any of them are answered without
+// asking the database. The entries expire with the cache TTL.
+func WithCache(c cache.Cache) Option {
+	return func(s *service) {
+		s.cache = c
+	}
 }
 
-func NewService(dgzClient dragonball.Client, repository Repository) Service {
-	return &service{
+// WithMissStore remembers the names the api does not know, so they are
+// answered as not found without calling out until they expire
+func WithMissStore(store MissStore) Option {
+	return func(s *service) {
+		s.misses = store
+	}
+}
+
+// WithLogger sets the logger of the service, slog.Default() if not set
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *service) {
+		s.logger = logger
+	}
+}
+
+// WithMetrics makes the service report to the given metrics
+func WithMetrics(metrics *Metrics) Option {
+	return func(s *service) {
+		s.metrics = metrics
+	}
+}
+
+func NewService(dgzClient dragonball.Client, repository Repository, opts ...Option) Service {
+	s := &service{
 		dgzClient:  dgzClient,
 		repository: repository,
+		metrics:    &Metrics{},
+		logger:     slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
-// GetByName retrieves a character by name (case-insensitive)
-func (s *service) GetByName(name string) (*Character, error) {
+// GetByName retrieves the character that best matches the name (case-insensitive).
+// An exact match always wins. Only fresh exact matches are trusted from the
+// local database, otherwise the api is asked for every match so a cached
+// "Goku" does not hide "Gohan" when searching "Go".
+func (s *service) GetByName(ctx context.Context, name string, opts LookupOptions) (*Character, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "character.GetByName", trace.WithAttributes(
+		attribute.String("character.name", name),
+		attribute.String("character.match", string(opts.Match)),
+		attribute.Bool("character.refresh", opts.Refresh),
+	))
+	defer span.End()
+
+	character, source, err := s.getByName(ctx, name, opts)
+
+	s.countLookup(source)
+	if source != "" {
+		logging.Annotate(ctx, slog.String("cache_source", string(source)))
+		span.SetAttributes(
+			attribute.String("source", string(source)),
+			attribute.Bool("cache_hit", source.IsCacheHit()),
+		)
+	}
+	if err != nil && !errors.Is(err, ErrCharacterNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return character, err
+}
+
+// getByName looks up the character, telling where it was found
+func (s *service) getByName(ctx context.Context, name string, opts LookupOptions) (*Character, Source, error) {
 
 	if name == "" {
-		return nil, ErrNameEmpty
+		return nil, "", ErrNameEmpty
+	}
+
+	mode := opts.Match
+	if mode == "" {
+		mode = DefaultMatchMode
 	}
 
+	// Another replica may have fetched the exact character already
+	if !opts.Refresh {
+		if shared := s.cachedByName(ctx, name); shared != nil && !shared.IsStale(s.cacheTTL) {
+			return shared, SourceSharedCache, nil
+		}
+	}
+
+	// Try to find the exact character in the local database
+	cached, err := s.repository.FindByName(ctx, name, MatchExact)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	if cached != nil && !opts.Refresh && !cached.IsStale(s.cacheTTL) {
+		s.cacheByName(ctx, cached)
+		return cached, SourceDatabase, nil
+	}
+
+	// Do not ask the api again about a name it did not know
+	if cached == nil && !opts.Refresh && s.knownMiss(ctx, name) {
+		return nil, SourceNegativeCache, ErrCharacterNotFound
+	}
+
+	// Fetch every match from external API
+	characters, err := s.searchUpstream(ctx, name)
+	if errors.Is(err, ErrDatabase) {
+		return nil, SourceUpstream, err
+	}
+	if err != nil {
+		// A stale copy is better than nothing if the api is not available
+		if cached != nil {
+			return cached, SourceStale, nil
+		}
+		if mode == MatchExact {
+			return nil, SourceUpstream, dragonball.WrapError(err)
+		}
+		// Serve the best partial match we have locally
+		character, dbErr := s.repository.FindByName(ctx, name, mode)
+		if dbErr != nil || character == nil {
+			return nil, SourceUpstream, dragonball.WrapError(err)
+		}
+		return character, SourceStale, nil
+	}
+
+	// The matches are shared with the other callers, rank a copy
+	character := bestMatch(name, mode, slices.Clone(characters))
+	if character == nil {
+		return nil, SourceUpstream, ErrCharacterNotFound
+	}
+
+	// Validate the API response
+	if !character.IsValid() {
+		return nil, SourceUpstream, fmt.Errorf("%w: character data is invalid received from the api", ErrInvalidCharacter)
+	}
+
+	return character, SourceUpstream, nil
+}
+
+// countLookup counts where GetByName answered from
+func (s *service) countLookup(source Source) {
+	switch source {
+	case SourceSharedCache:
+		s.metrics.SharedCacheHits.Add(1)
+	case SourceDatabase:
+		s.metrics.DatabaseHits.Add(1)
+	case SourceNegativeCache:
+		s.metrics.NegativeHits.Add(1)
+	case SourceUpstream, SourceStale:
+		s.metrics.CacheMisses.Add(1)
+	}
+}
+
+// GetByID retrieves a character by its upstream id
+func (s *service) GetByID(ctx context.Context, id int) (*Character, error) {
 	// Try to find the character in the local database
-	character, err := s.repository.FindByName(name)
+	cached, err := s.repository.FindByID(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
 	}
 
-	if character != nil {
-		return character, nil
+	if cached != nil && !cached.IsStale(s.cacheTTL) {
+		return cached, nil
 	}
 
 	// Fetch from external API
-	apiCharacter, err := s.dgzClient.GetCharacterByName(name)
+	detail, err := s.dgzClient.GetCharacterByID(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("external API error: %w", err)
+		// A stale copy is better than nothing if the api is not available
+		if cached != nil {
+			return cached, nil
+		}
+		return nil, dragonball.WrapError(err)
 	}
-	if apiCharacter == nil {
+	if detail == nil {
 		return nil, ErrCharacterNotFound
 	}
 
-	character = FromAPIResponse(apiCharacter)
+	character := FromAPIDetail(detail)
 
 	// Validate the API response
 	if !character.IsValid() {
 		return nil, fmt.Errorf("%w: character data is invalid received from the api", ErrInvalidCharacter)
 	}
 
-	if err := s.repository.Save(character); err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	if err := s.repository.Save(ctx, character); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
 	}
+	s.cacheByName(ctx, character)
 
 	return character, nil
 }
 
-// GetAll retrieves all characters from the local database
-func (s *service) GetAll() ([]*Character, error) {
-	characters, err := s.repository.FindAll()
+// Search retrieves every character matching the name, exact matches first.
+// All the matches returned by the api are saved in the local database.
+func (s *service) Search(ctx context.Context, name string) ([]*Character, error) {
+
+	if name == "" {
+		return nil, ErrNameEmpty
+	}
+
+	if s.knownMiss(ctx, name) {
+		return nil, ErrCharacterNotFound
+	}
+
+	characters, err := s.searchUpstream(ctx, name)
+	if errors.Is(err, ErrDatabase) {
+		return nil, err
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all characters: %w", err)
+		// Serve what we have locally if the api is not available
+		characters, dbErr := s.repository.SearchByName(ctx, name)
+		if dbErr != nil || len(characters) == 0 {
+			return nil, dragonball.WrapError(err)
+		}
+		rankByName(name, characters)
+		return characters, nil
 	}
+
+	// Skip invalid entries instead of failing the whole search
+	characters = validCharacters(characters)
+
+	if len(characters) == 0 {
+		return nil, ErrCharacterNotFound
+	}
+
+	rankByName(name, characters)
+
 	return characters, nil
 }
+
+// GetTransformations retrieves the transformations of the character with the id.
+// They are fetched from the api the first time and then served from the local database.
+func (s *service) GetTransformations(ctx context.Context, id int) ([]*Transformation, error) {
+	character, err := s.repository.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	if character != nil && character.TransformationsFetched {
+		transformations, err := s.repository.FindTransformations(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+		return transformations, nil
+	}
+
+	// Fetch the character detail from external API
+	detail, err := s.dgzClient.GetCharacterByID(ctx, id)
+	if err != nil {
+		return nil, dragonball.WrapError(err)
+	}
+	if detail == nil {
+		return nil, ErrCharacterNotFound
+	}
+
+	character = FromAPIDetail(detail)
+	if !character.IsValid() {
+		return nil, fmt.Errorf("%w: character data is invalid received from the api", ErrInvalidCharacter)
+	}
+
+	transformations := make([]*Transformation, 0, len(detail.Transformations))
+	for _, transformation := range TransformationsFromAPIResponse(character.ID, detail.Transformations) {
+		if transformation.IsValid() {
+			transformations = append(transformations, transformation)
+		}
+	}
+
+	if err := s.repository.SaveTransformations(ctx, character, transformations); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+	// The character row is saved too, the cached copy may be outdated
+	s.cacheByName(ctx, character)
+
+	return transformations, nil
+}
+
+// GetAll retrieves a page of characters from the local database
+func (s *service) GetAll(ctx context.Context, params ListParams) (*Page, error) {
+	params = params.Normalize()
+
+	characters, total, err := s.repository.FindAll(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get all characters: %w", ErrDatabase, err)
+	}
+	if characters == nil {
+		characters = []*Character{}
+	}
+
+	return &Page{
+		Items: characters,
+		Total: total,
+		Page:  params.Page,
+		Limit: params.Limit,
+	}, nil
+}
+
+// searchUpstream fetches every match of the name from the api and caches the
+// valid ones, refreshing the ones already stored, so later exact lookups are
+// answered locally. Concurrent calls for the same name share a single fetch
+// and a single write; the returned slice is shared and must not be modified.
+// Errors saving are wrapped with ErrDatabase, the errors of the api are returned as is.
+func (s *service) searchUpstream(ctx context.Context, name string) ([]*Character, error) {
+	key := normalizeName(name)
+
+	leader := false
+	result := s.searches.DoChan(key, func() (interface{}, error) {
+		leader = true
+		s.metrics.UpstreamSearches.Add(1)
+
+		// The fetch must not fail for everyone if the caller that started it goes away
+		ctx := context.WithoutCancel(ctx)
+
+		apiCharacters, err := s.dgzClient.SearchCharactersByName(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if len(apiCharacters) == 0 {
+			s.rememberMiss(ctx, name)
+		} else {
+			s.forgetMiss(ctx, name)
+		}
+
+		characters := make([]*Character, 0, len(apiCharacters))
+		for _, apiCharacter := range apiCharacters {
+			characters = append(characters, FromAPIResponse(apiCharacter))
+		}
+
+		if valid := validCharacters(characters); len(valid) > 0 {
+			if err := s.repository.SaveAll(ctx, valid); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+			}
+			s.cacheByName(ctx, valid...)
+		}
+		return characters, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-result:
+		// leader is written before the result is sent
+		if !leader {
+			s.metrics.CoalescedCalls.Add(1)
+		}
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.([]*Character), nil
+	}
+}
+
+// PurgeMisses forgets the name in the negative cache, or every name if it is empty.
+// It returns how many names were forgotten.
+func (s *service) PurgeMisses(ctx context.Context, name string) (int64, error) {
+	if s.misses == nil {
+		return 0, nil
+	}
+
+	if name == "" {
+		removed, err := s.misses.Purge(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+		return removed, nil
+	}
+
+	known, err := s.misses.Has(ctx, name)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+	if err := s.misses.Delete(ctx, name); err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+	if !known {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+// cachedByName returns the character stored in the shared cache, if any.
+// The shared cache is best effort, its errors are only logged.
+func (s *service) cachedByName(ctx context.Context, name string) *Character {
+	if s.cache == nil {
+		return nil
+	}
+
+	data, ok, err := s.cache.Get(ctx, NameCacheKey(name))
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to read from the cache", "name", name, "error", err)
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+
+	var character Character
+	if err := json.Unmarshal(data, &character); err != nil {
+		s.logger.WarnContext(ctx, "failed to decode from the cache", "name", name, "error", err)
+		return nil
+	}
+	return &character
+}
+
+// cacheByName stores the characters in the shared cache
+func (s *service) cacheByName(ctx context.Context, characters ...*Character) {
+	if s.cache == nil {
+		return
+	}
+
+	for _, character := range characters {
+		data, err := json.Marshal(character)
+		if err != nil {
+			s.logger.WarnContext(ctx, "failed to encode for the cache", "name", character.Name, "error", err)
+			continue
+		}
+		if err := s.cache.Set(ctx, NameCacheKey(character.Name), data, s.cacheTTL); err != nil {
+			s.logger.WarnContext(ctx, "failed to write to the cache", "name", character.Name, "error", err)
+		}
+	}
+}
+
+// knownMiss reports whether the api recently did not know the name.
+// The negative cache is best effort, its errors are only logged.
+func (s *service) knownMiss(ctx context.Context, name string) bool {
+	if s.misses == nil {
+		return false
+	}
+	known, err := s.misses.Has(ctx, name)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to check the negative cache", "name", name, "error", err)
+		return false
+	}
+	return known
+}
+
+// rememberMiss adds the name to the negative cache
+func (s *service) rememberMiss(ctx context.Context, name string) {
+	if s.misses == nil {
+		return
+	}
+	if err := s.misses.Add(ctx, name); err != nil {
+		s.logger.WarnContext(ctx, "failed to add to the negative cache", "name", name, "error", err)
+	}
+}
+
+// forgetMiss removes the name from the negative cache once the api knows it
+func (s *service) forgetMiss(ctx context.Context, name string) {
+	if s.misses == nil {
+		return
+	}
+	if err := s.misses.Delete(ctx, name); err != nil {
+		s.logger.WarnContext(ctx, "failed to remove from the negative cache", "name", name, "error", err)
+	}
+}
+
+// validCharacters returns only the characters that can be stored
+func validCharacters(characters []*Character) []*Character {
+	valid := make([]*Character, 0, len(characters))
+	for _, character := range characters {
+		if character.IsValid() {
+			valid = append(valid, character)
+		}
+	}
+	return valid
+}
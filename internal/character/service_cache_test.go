@@ -0,0 +1,166 @@
+package character_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/cache"
+	"github.com/gclamigueiro/dragon-ball-api/internal/cache/resptest"
+	"github.com/gclamigueiro/dragon-ball-api/internal/character"
+	"github.com/gclamigueiro/dragon-ball-api/internal/character/mocks"
+	"github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
+	mock_dragonball "github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball/mocks"
+)
+
+// sharedRedis starts an in-process RESP server and returns a client for it
+func sharedRedis(t *testing.T) *cache.Redis {
+	server, err := resptest.NewServer()
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+
+	c := cache.NewRedis(cache.RedisOptions{Addr: server.Addr()})
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+// replica builds a service with its own database and api mocks and the given shared cache
+func replica(c cache.Cache) (character.Service, *mocks.Repository, *mock_dragonball.Client) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo,
+		character.WithCacheTTL(time.Hour),
+		character.WithCache(c),
+		character.WithMissStore(character.NewCacheMissStore(c, time.Hour)),
+	)
+	return svc, mockRepo, mockClient
+}
+
+func TestService_SharedCache_CharacterFetchedByOtherReplica(t *testing.T) {
+	shared := sharedRedis(t)
+	ctx := context.Background()
+
+	first, firstRepo, firstClient := replica(shared)
+	firstRepo.On("FindByName", mock.Anything, "Vegeta", character.MatchExact).Return(nil, nil)
+	firstClient.On("SearchCharactersByName", mock.Anything, "Vegeta").Return([]*dragonball.Character{{ID: 2, Name: "Vegeta", Ki: "54.000.000"}}, nil)
+	firstRepo.On("SaveAll", mock.Anything, mock.AnythingOfType("[]*character.Character")).Return(nil)
+
+	result, err := first.GetByName(ctx, "Vegeta", character.LookupOptions{Match: character.MatchExact})
+	require.NoError(t, err)
+	assert.Equal(t, "Vegeta", result.Name)
+
+	// The second replica answers from the shared cache, without the database or the api
+	second, secondRepo, secondClient := replica(shared)
+
+	result, err = second.GetByName(ctx, "VEGETA", character.LookupOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.ID)
+	assert.Equal(t, "54000000", result.KiNumeric.String())
+
+	firstRepo.AssertExpectations(t)
+	firstClient.AssertExpectations(t)
+	secondRepo.AssertExpectations(t)
+	secondClient.AssertExpectations(t)
+}
+
+func TestService_SharedCache_RefreshSkipsCache(t *testing.T) {
+	shared := sharedRedis(t)
+	ctx := context.Background()
+
+	svc, mockRepo, mockClient := replica(shared)
+	mockRepo.On("FindByName", mock.Anything, "Goku", character.MatchExact).Return(nil, nil)
+	mockClient.On("SearchCharactersByName", mock.Anything, "Goku").Return([]*dragonball.Character{{ID: 1, Name: "Goku", Ki: "60.000.000"}}, nil).Once()
+	mockRepo.On("SaveAll", mock.Anything, mock.AnythingOfType("[]*character.Character")).Return(nil)
+
+	_, err := svc.GetByName(ctx, "Goku", character.LookupOptions{})
+	require.NoError(t, err)
+
+	// The api corrected the ki, a refresh fetches it and updates the shared cache
+	mockClient.On("SearchCharactersByName", mock.Anything, "Goku").Return([]*dragonball.Character{{ID: 1, Name: "Goku", Ki: "90.000.000"}}, nil).Once()
+	result, err := svc.GetByName(ctx, "Goku", character.LookupOptions{Refresh: true})
+	require.NoError(t, err)
+	assert.Equal(t, "90.000.000", result.Ki)
+
+	result, err = svc.GetByName(ctx, "Goku", character.LookupOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "90.000.000", result.Ki)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_SharedCache_TransformationsUpdateCache(t *testing.T) {
+	shared := sharedRedis(t)
+	ctx := context.Background()
+
+	svc, mockRepo, mockClient := replica(shared)
+	mockRepo.On("FindByName", mock.Anything, "Goku", character.MatchExact).Return(nil, nil)
+	mockClient.On("SearchCharactersByName", mock.Anything, "Goku").Return([]*dragonball.Character{{ID: 1, Name: "Goku", Ki: "60.000.000"}}, nil)
+	mockRepo.On("SaveAll", mock.Anything, mock.AnythingOfType("[]*character.Character")).Return(nil)
+
+	_, err := svc.GetByName(ctx, "Goku", character.LookupOptions{})
+	require.NoError(t, err)
+
+	// Fetching the transformations saves the character with the new ki
+	mockRepo.On("FindByID", mock.Anything, 1).Return(&character.Character{ID: 1, Name: "Goku", Ki: "60.000.000"}, nil)
+	mockClient.On("GetCharacterByID", mock.Anything, 1).Return(&dragonball.CharacterDetail{
+		Character:       dragonball.Character{ID: 1, Name: "Goku", Ki: "90.000.000"},
+		Transformations: []*dragonball.Transformation{{ID: 1, Name: "Goku SSJ", Ki: "3 Billion"}},
+	}, nil)
+	mockRepo.On("SaveTransformations", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	_, err = svc.GetTransformations(ctx, 1)
+	require.NoError(t, err)
+
+	result, err := svc.GetByName(ctx, "Goku", character.LookupOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "90.000.000", result.Ki)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_SharedCache_MissSeenByOtherReplica(t *testing.T) {
+	shared := sharedRedis(t)
+	ctx := context.Background()
+
+	first, firstRepo, firstClient := replica(shared)
+	firstRepo.On("FindByName", mock.Anything, "Gokku", character.MatchExact).Return(nil, nil)
+	firstClient.On("SearchCharactersByName", mock.Anything, "Gokku").Return([]*dragonball.Character{}, nil)
+
+	_, err := first.GetByName(ctx, "Gokku", character.LookupOptions{})
+	assert.ErrorIs(t, err, character.ErrCharacterNotFound)
+
+	second, secondRepo, secondClient := replica(shared)
+	secondRepo.On("FindByName", mock.Anything, "gokku", character.MatchExact).Return(nil, nil)
+
+	_, err = second.GetByName(ctx, "gokku", character.LookupOptions{})
+	assert.ErrorIs(t, err, character.ErrCharacterNotFound)
+
+	purged, err := second.PurgeMisses(ctx, "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	firstClient.AssertExpectations(t)
+	secondRepo.AssertExpectations(t)
+	secondClient.AssertExpectations(t)
+}
+
+func TestService_SharedCache_Unavailable(t *testing.T) {
+	server, err := resptest.NewServer()
+	require.NoError(t, err)
+	shared := cache.NewRedis(cache.RedisOptions{Addr: server.Addr()})
+	server.Close()
+	ctx := context.Background()
+
+	// A cache that is down does not break the lookups
+	svc, mockRepo, mockClient := replica(shared)
+	mockRepo.On("FindByName", mock.Anything, "Goku", character.MatchExact).Return(&character.Character{ID: 1, Name: "Goku", FetchedAt: time.Now()}, nil)
+
+	result, err := svc.GetByName(ctx, "Goku", character.LookupOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Goku", result.Name)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
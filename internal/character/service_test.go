@@ -1,13 +1,18 @@
 package character_test
 
 import (
+	"context"
 	"errors"
+	"log/slog"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gclamigueiro/dragon-ball-api/internal/character"
 	"github.com/gclamigueiro/dragon-ball-api/internal/character/mocks"
 	"github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
 	mock_dragonball "github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball/mocks"
+	"github.com/gclamigueiro/dragon-ball-api/internal/logging"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -16,11 +21,12 @@ func TestService_GetByName_FoundInRepository(t *testing.T) {
 	mockRepo := new(mocks.Repository)
 	mockClient := new(mock_dragonball.Client)
 	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
 
 	expected := &character.Character{Name: "Goku"}
-	mockRepo.On("FindByName", "Goku").Return(expected, nil)
+	mockRepo.On("FindByName", mock.Anything, "Goku", character.MatchExact).Return(expected, nil)
 
-	result, err := svc.GetByName("Goku")
+	result, err := svc.GetByName(ctx, "Goku", character.LookupOptions{Match: character.MatchPrefix})
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 	mockRepo.AssertExpectations(t)
@@ -31,16 +37,178 @@ func TestService_GetByName_FoundInAPI(t *testing.T) {
 	mockRepo := new(mocks.Repository)
 	mockClient := new(mock_dragonball.Client)
 	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
 
-	mockRepo.On("FindByName", "Vegeta").Return(nil, nil)
-	apiChar := &dragonball.Character{ID: 2, Name: "Vegeta"}
-	mockClient.On("GetCharacterByName", "Vegeta").Return(apiChar, nil)
-	mockRepo.On("Save", mock.AnythingOfType("*character.Character")).Return(nil)
+	mockRepo.On("FindByName", mock.Anything, "Vegeta", character.MatchExact).Return(nil, nil)
+	apiChars := []*dragonball.Character{{
+		ID:          2,
+		Name:        "Vegeta",
+		Ki:          "54.000.000",
+		MaxKi:       "19.84 Septillion",
+		Race:        "Saiyan",
+		Gender:      "Male",
+		Image:       "https://dragonball-api.com/characters/vegeta_normal.webp",
+		Affiliation: "Z Fighter",
+	}}
+	mockClient.On("SearchCharactersByName", mock.Anything, "Vegeta").Return(apiChars, nil)
+	mockRepo.On("SaveAll", mock.Anything, mock.AnythingOfType("[]*character.Character")).Return(nil)
 
-	result, err := svc.GetByName("Vegeta")
+	result, err := svc.GetByName(ctx, "Vegeta", character.LookupOptions{Match: character.MatchPrefix})
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.Equal(t, "Vegeta", result.Name)
+	assert.Equal(t, "19.84 Septillion", result.MaxKi)
+	assert.Equal(t, "Male", result.Gender)
+	assert.Equal(t, "https://dragonball-api.com/characters/vegeta_normal.webp", result.Image)
+	assert.Equal(t, "Z Fighter", result.Affiliation)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetByName_PrefersExactMatchFromAPI(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("FindByName", mock.Anything, "Gohan", character.MatchExact).Return(nil, nil)
+	apiChars := []*dragonball.Character{
+		{ID: 40, Name: "Gohan del Futuro"},
+		{ID: 5, Name: "Gohan"},
+	}
+	mockClient.On("SearchCharactersByName", mock.Anything, "Gohan").Return(apiChars, nil)
+	mockRepo.On("SaveAll", mock.Anything, mock.MatchedBy(func(chars []*character.Character) bool {
+		return len(chars) == 2
+	})).Return(nil)
+
+	result, err := svc.GetByName(ctx, "Gohan", character.LookupOptions{Match: character.MatchPrefix})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, result.ID)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetByName_ExactModeNoMatch(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("FindByName", mock.Anything, "Go", character.MatchExact).Return(nil, nil)
+	apiChars := []*dragonball.Character{{ID: 1, Name: "Goku"}, {ID: 5, Name: "Gohan"}}
+	mockClient.On("SearchCharactersByName", mock.Anything, "Go").Return(apiChars, nil)
+	// The matches are cached even if none of them is exact
+	mockRepo.On("SaveAll", mock.Anything, mock.AnythingOfType("[]*character.Character")).Return(nil)
+
+	result, err := svc.GetByName(ctx, "Go", character.LookupOptions{Match: character.MatchExact})
+	assert.ErrorIs(t, err, character.ErrCharacterNotFound)
+	assert.Nil(t, result)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetByName_ContainsMode(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("FindByName", mock.Anything, "han", character.MatchExact).Return(nil, nil)
+	apiChars := []*dragonball.Character{{ID: 5, Name: "Gohan"}}
+	mockClient.On("SearchCharactersByName", mock.Anything, "han").Return(apiChars, nil)
+	mockRepo.On("SaveAll", mock.Anything, mock.AnythingOfType("[]*character.Character")).Return(nil)
+
+	_, err := svc.GetByName(ctx, "han", character.LookupOptions{Match: character.MatchPrefix})
+	assert.ErrorIs(t, err, character.ErrCharacterNotFound)
+
+	result, err := svc.GetByName(ctx, "han", character.LookupOptions{Match: character.MatchContains})
+	assert.NoError(t, err)
+	assert.Equal(t, "Gohan", result.Name)
+}
+
+func TestService_GetByName_FallsBackToLocalPrefixMatch(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
+
+	expected := &character.Character{ID: 1, Name: "Goku"}
+	mockRepo.On("FindByName", mock.Anything, "Go", character.MatchExact).Return(nil, nil)
+	mockClient.On("SearchCharactersByName", mock.Anything, "Go").Return(nil, errors.New("timeout"))
+	mockRepo.On("FindByName", mock.Anything, "Go", character.MatchPrefix).Return(expected, nil)
+
+	result, err := svc.GetByName(ctx, "Go", character.LookupOptions{Match: character.MatchPrefix})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetByName_StaleIsRefreshed(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo, character.WithCacheTTL(time.Hour))
+	ctx := context.Background()
+
+	cached := &character.Character{ID: 1, Name: "Goku", Ki: "60.000.000", FetchedAt: time.Now().Add(-2 * time.Hour)}
+	mockRepo.On("FindByName", mock.Anything, "Goku", character.MatchExact).Return(cached, nil)
+	mockClient.On("SearchCharactersByName", mock.Anything, "Goku").Return([]*dragonball.Character{{ID: 1, Name: "Goku", Ki: "70.000.000"}}, nil)
+	mockRepo.On("SaveAll", mock.Anything, mock.AnythingOfType("[]*character.Character")).Return(nil)
+
+	result, err := svc.GetByName(ctx, "Goku", character.LookupOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "70.000.000", result.Ki)
+	assert.WithinDuration(t, time.Now(), result.FetchedAt, time.Minute)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetByName_FreshIsNotRefreshed(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo, character.WithCacheTTL(time.Hour))
+	ctx := context.Background()
+
+	cached := &character.Character{ID: 1, Name: "Goku", FetchedAt: time.Now().Add(-time.Minute)}
+	mockRepo.On("FindByName", mock.Anything, "Goku", character.MatchExact).Return(cached, nil)
+
+	result, err := svc.GetByName(ctx, "Goku", character.LookupOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, cached, result)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetByName_ForceRefresh(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
+
+	cached := &character.Character{ID: 1, Name: "Goku", FetchedAt: time.Now()}
+	mockRepo.On("FindByName", mock.Anything, "Goku", character.MatchExact).Return(cached, nil)
+	mockClient.On("SearchCharactersByName", mock.Anything, "Goku").Return([]*dragonball.Character{{ID: 1, Name: "Goku"}}, nil)
+	mockRepo.On("SaveAll", mock.Anything, mock.AnythingOfType("[]*character.Character")).Return(nil)
+
+	_, err := svc.GetByName(ctx, "Goku", character.LookupOptions{Refresh: true})
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetByName_ServesStaleWhenAPIFails(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo, character.WithCacheTTL(time.Hour))
+	ctx := context.Background()
+
+	cached := &character.Character{ID: 1, Name: "Goku"}
+	mockRepo.On("FindByName", mock.Anything, "Goku", character.MatchExact).Return(cached, nil)
+	mockClient.On("SearchCharactersByName", mock.Anything, "Goku").Return(nil, errors.New("timeout"))
+
+	result, err := svc.GetByName(ctx, "Goku", character.LookupOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, cached, result)
 	mockRepo.AssertExpectations(t)
 	mockClient.AssertExpectations(t)
 }
@@ -49,8 +217,9 @@ func TestService_GetByName_EmptyName(t *testing.T) {
 	mockRepo := new(mocks.Repository)
 	mockClient := new(mock_dragonball.Client)
 	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
 
-	result, err := svc.GetByName("")
+	result, err := svc.GetByName(ctx, "", character.LookupOptions{Match: character.MatchPrefix})
 	assert.ErrorIs(t, err, character.ErrNameEmpty)
 	assert.Nil(t, result)
 }
@@ -59,11 +228,12 @@ func TestService_GetByName_NotFound(t *testing.T) {
 	mockRepo := new(mocks.Repository)
 	mockClient := new(mock_dragonball.Client)
 	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
 
-	mockRepo.On("FindByName", "Piccolo").Return(nil, nil)
-	mockClient.On("GetCharacterByName", "Piccolo").Return(nil, nil)
+	mockRepo.On("FindByName", mock.Anything, "Piccolo", character.MatchExact).Return(nil, nil)
+	mockClient.On("SearchCharactersByName", mock.Anything, "Piccolo").Return([]*dragonball.Character{}, nil)
 
-	result, err := svc.GetByName("Piccolo")
+	result, err := svc.GetByName(ctx, "Piccolo", character.LookupOptions{Match: character.MatchPrefix})
 	assert.ErrorIs(t, err, character.ErrCharacterNotFound)
 	assert.Nil(t, result)
 }
@@ -72,13 +242,43 @@ func TestService_GetAll_Success(t *testing.T) {
 	mockRepo := new(mocks.Repository)
 	mockClient := new(mock_dragonball.Client)
 	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
 
 	expected := []*character.Character{{Name: "Goku"}, {Name: "Vegeta"}}
-	mockRepo.On("FindAll").Return(expected, nil)
+	params := character.ListParams{
+		Page:   2,
+		Limit:  2,
+		Filter: character.ListFilter{Race: "Saiyan"},
+		Sort:   character.SortOrder{Field: "ki", Desc: true},
+	}
+	mockRepo.On("FindAll", mock.Anything, params).Return(expected, int64(4), nil)
 
-	result, err := svc.GetAll()
+	result, err := svc.GetAll(ctx, params)
 	assert.NoError(t, err)
-	assert.Equal(t, expected, result)
+	assert.Equal(t, expected, result.Items)
+	assert.Equal(t, int64(4), result.Total)
+	assert.Equal(t, 2, result.Page)
+	assert.Equal(t, 2, result.Limit)
+	assert.False(t, result.HasNext())
+	assert.True(t, result.HasPrev())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_GetAll_DefaultParams(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
+
+	params := character.ListParams{Page: 1, Limit: character.MaxPageLimit, Sort: character.SortOrder{Field: "id"}}
+	mockRepo.On("FindAll", mock.Anything, params).Return(nil, int64(0), nil)
+
+	result, err := svc.GetAll(ctx, character.ListParams{Limit: 1000})
+	assert.NoError(t, err)
+	assert.NotNil(t, result.Items)
+	assert.Empty(t, result.Items)
+	assert.Equal(t, 1, result.Page)
+	assert.Equal(t, character.MaxPageLimit, result.Limit)
 	mockRepo.AssertExpectations(t)
 }
 
@@ -86,11 +286,497 @@ func TestService_GetAll_Error(t *testing.T) {
 	mockRepo := new(mocks.Repository)
 	mockClient := new(mock_dragonball.Client)
 	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
 
-	mockRepo.On("FindAll").Return(nil, errors.New("db error"))
+	params := character.ListParams{Page: 1, Limit: character.DefaultPageLimit, Sort: character.SortOrder{Field: "id"}}
+	mockRepo.On("FindAll", mock.Anything, params).Return(nil, int64(0), errors.New("db error"))
 
-	result, err := svc.GetAll()
+	result, err := svc.GetAll(ctx, character.ListParams{})
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestService_Search_RanksExactMatchFirst(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
+
+	apiChars := []*dragonball.Character{
+		{ID: 1, Name: "Goku"},
+		{ID: 5, Name: "Gohan"},
+		{ID: 40, Name: "Super Gohan"},
+		{ID: 41, Name: "gohan"},
+	}
+	mockClient.On("SearchCharactersByName", mock.Anything, "Gohan").Return(apiChars, nil)
+	mockRepo.On("SaveAll", mock.Anything, mock.MatchedBy(func(chars []*character.Character) bool {
+		return len(chars) == 4
+	})).Return(nil)
+
+	result, err := svc.Search(ctx, "Gohan")
+	assert.NoError(t, err)
+	assert.Len(t, result, 4)
+	assert.Equal(t, []string{"Gohan", "gohan", "Super Gohan", "Goku"}, []string{
+		result[0].Name, result[1].Name, result[2].Name, result[3].Name,
+	})
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_Search_RanksByCharactersNotBytes(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
+
+	// Both names are 9 bytes long, but "Son Gokū" only has 8 characters
+	apiChars := []*dragonball.Character{
+		{ID: 5, Name: "Son Gohan"},
+		{ID: 1, Name: "Son Gokū"},
+	}
+	mockClient.On("SearchCharactersByName", mock.Anything, "Son").Return(apiChars, nil)
+	mockRepo.On("SaveAll", mock.Anything, mock.Anything).Return(nil)
+
+	result, err := svc.Search(ctx, "Son")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Son Gokū", "Son Gohan"}, []string{result[0].Name, result[1].Name})
+}
+
+func TestService_Search_NotFound(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
+
+	mockClient.On("SearchCharactersByName", mock.Anything, "Zzz").Return([]*dragonball.Character{}, nil)
+
+	result, err := svc.Search(ctx, "Zzz")
+	assert.ErrorIs(t, err, character.ErrCharacterNotFound)
+	assert.Nil(t, result)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_Search_FallsBackToRepository(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
+
+	local := []*character.Character{{ID: 1, Name: "Goku"}, {ID: 5, Name: "Go"}}
+	mockClient.On("SearchCharactersByName", mock.Anything, "Go").Return(nil, errors.New("timeout"))
+	mockRepo.On("SearchByName", mock.Anything, "Go").Return(local, nil)
+
+	result, err := svc.Search(ctx, "Go")
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, "Go", result[0].Name)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_Search_EmptyName(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
+
+	result, err := svc.Search(ctx, "")
+	assert.ErrorIs(t, err, character.ErrNameEmpty)
+	assert.Nil(t, result)
+}
+
+func TestService_GetTransformations_Cached(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
+
+	expected := []*character.Transformation{{ID: 1, CharacterID: 1, Name: "Goku SSJ"}}
+	mockRepo.On("FindByID", mock.Anything, 1).Return(&character.Character{ID: 1, Name: "Goku", TransformationsFetched: true}, nil)
+	mockRepo.On("FindTransformations", mock.Anything, 1).Return(expected, nil)
+
+	result, err := svc.GetTransformations(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetTransformations_FetchedFromAPI(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("FindByID", mock.Anything, 1).Return(&character.Character{ID: 1, Name: "Goku"}, nil)
+	detail := &dragonball.CharacterDetail{
+		Character:    dragonball.Character{ID: 1, Name: "Goku", Ki: "60.000.000"},
+		OriginPlanet: &dragonball.Planet{ID: 2, Name: "Tierra"},
+		Transformations: []*dragonball.Transformation{
+			{ID: 1, Name: "Goku SSJ", Ki: "3 Billion"},
+			{ID: 2, Name: "Goku SSJ2", Ki: "6 Billion"},
+		},
+	}
+	mockClient.On("GetCharacterByID", mock.Anything, 1).Return(detail, nil)
+	mockRepo.On("SaveTransformations", mock.Anything,
+		mock.MatchedBy(func(c *character.Character) bool { return c.ID == 1 && *c.OriginPlanetID == 2 }),
+		mock.MatchedBy(func(ts []*character.Transformation) bool { return len(ts) == 2 }),
+	).Return(nil)
+
+	result, err := svc.GetTransformations(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, 1, result[0].CharacterID)
+	assert.Equal(t, "3000000000", result[0].KiNumeric.String())
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetTransformations_NotFound(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("FindByID", mock.Anything, 999).Return(nil, nil)
+	mockClient.On("GetCharacterByID", mock.Anything, 999).Return(nil, nil)
+
+	result, err := svc.GetTransformations(ctx, 999)
+	assert.ErrorIs(t, err, character.ErrCharacterNotFound)
+	assert.Nil(t, result)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetByID_FoundInRepository(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
+
+	expected := &character.Character{ID: 1, Name: "Goku"}
+	mockRepo.On("FindByID", mock.Anything, 1).Return(expected, nil)
+
+	result, err := svc.GetByID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetByID_FoundInAPI(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("FindByID", mock.Anything, 2).Return(nil, nil)
+	detail := &dragonball.CharacterDetail{
+		Character:    dragonball.Character{ID: 2, Name: "Vegeta"},
+		OriginPlanet: &dragonball.Planet{ID: 3, Name: "Vegeta"},
+	}
+	mockClient.On("GetCharacterByID", mock.Anything, 2).Return(detail, nil)
+	mockRepo.On("Save", mock.Anything, mock.MatchedBy(func(c *character.Character) bool {
+		return c.ID == 2 && *c.OriginPlanetID == 3
+	})).Return(nil)
+
+	result, err := svc.GetByID(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "Vegeta", result.Name)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetByID_NotFound(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("FindByID", mock.Anything, 999).Return(nil, nil)
+	mockClient.On("GetCharacterByID", mock.Anything, 999).Return(nil, nil)
+
+	result, err := svc.GetByID(ctx, 999)
+	assert.ErrorIs(t, err, character.ErrCharacterNotFound)
+	assert.Nil(t, result)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetByName_CancelledContext(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The context carries the span of the lookup, but must keep the cancellation
+	cancelled := mock.MatchedBy(func(c context.Context) bool { return errors.Is(c.Err(), context.Canceled) })
+	mockRepo.On("FindByName", cancelled, "Goku", character.MatchExact).Return(nil, context.Canceled)
+
+	result, err := svc.GetByName(ctx, "Goku", character.LookupOptions{})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, err, character.ErrDatabase)
+	assert.Nil(t, result)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetByName_UpstreamUnavailable(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("FindByName", mock.Anything, "Broly", character.MatchExact).Return(nil, nil)
+	mockClient.On("SearchCharactersByName", mock.Anything, "Broly").Return(nil, dragonball.ErrCircuitOpen)
+	mockRepo.On("FindByName", mock.Anything, "Broly", character.MatchPrefix).Return(nil, nil)
+
+	result, err := svc.GetByName(ctx, "Broly", character.LookupOptions{})
+	assert.ErrorIs(t, err, dragonball.ErrUnavailable)
+	assert.Nil(t, result)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetByID_KeepsUpstreamError(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
+
+	apiErr := &dragonball.APIError{StatusCode: 429, URL: "http://api/characters/1"}
+	mockRepo.On("FindByID", mock.Anything, 1).Return(nil, nil)
+	mockClient.On("GetCharacterByID", mock.Anything, 1).Return(nil, apiErr)
+
+	result, err := svc.GetByID(ctx, 1)
+	assert.ErrorIs(t, err, dragonball.ErrRateLimited)
+	assert.NotErrorIs(t, err, dragonball.ErrUnavailable)
+	assert.Nil(t, result)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_Search_UpstreamUnavailableServesCache(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
+
+	local := []*character.Character{{ID: 1, Name: "Goku"}}
+	mockClient.On("SearchCharactersByName", mock.Anything, "Go").Return(nil, dragonball.ErrCircuitOpen)
+	mockRepo.On("SearchByName", mock.Anything, "Go").Return(local, nil)
+
+	result, err := svc.Search(ctx, "Go")
+	assert.NoError(t, err)
+	assert.Equal(t, local, result)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetByName_CoalescesConcurrentMisses(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	metrics := &character.Metrics{}
+	svc := character.NewService(mockClient, mockRepo, character.WithMetrics(metrics))
+	ctx := context.Background()
+
+	const callers = 50
+	var lookups sync.WaitGroup
+	lookups.Add(callers)
+	release := make(chan struct{})
+
+	mockRepo.On("FindByName", mock.Anything, mock.Anything, character.MatchExact).
+		Run(func(mock.Arguments) { lookups.Done() }).
+		Return(nil, nil)
+	mockClient.On("SearchCharactersByName", mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) { <-release }).
+		Return([]*dragonball.Character{{ID: 4, Name: "Broly"}}, nil).Once()
+	mockRepo.On("SaveAll", mock.Anything, mock.AnythingOfType("[]*character.Character")).Return(nil).Once()
+
+	var wg sync.WaitGroup
+	results := make([]*character.Character, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		// Different spellings of the same name share the fetch
+		name := "Broly"
+		if i%2 == 1 {
+			name = "BROLY"
+		}
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			results[i], errs[i] = svc.GetByName(ctx, name, character.LookupOptions{Match: character.MatchExact})
+		}(i, name)
+	}
+
+	// Let every caller join the search in flight before the api answers
+	lookups.Wait()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i := 0; i < callers; i++ {
+		assert.NoError(t, errs[i])
+		if assert.NotNil(t, results[i]) {
+			assert.Equal(t, "Broly", results[i].Name)
+		}
+	}
+	assert.Equal(t, int64(1), metrics.UpstreamSearches.Load())
+	assert.Equal(t, int64(callers-1), metrics.CoalescedCalls.Load())
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetByName_CoalescedCallerCanLeave(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+
+	release := make(chan struct{})
+	saved := make(chan struct{})
+	mockRepo.On("FindByName", mock.Anything, "Broly", character.MatchExact).Return(nil, nil)
+	mockClient.On("SearchCharactersByName", mock.Anything, "Broly").
+		Run(func(mock.Arguments) { <-release }).
+		Return([]*dragonball.Character{{ID: 4, Name: "Broly"}}, nil)
+	mockRepo.On("SaveAll", mock.Anything, mock.AnythingOfType("[]*character.Character")).
+		Run(func(mock.Arguments) { close(saved) }).
+		Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	// The caller that started the fetch goes away without waiting for the api...
+	result, err := svc.GetByName(ctx, "Broly", character.LookupOptions{Match: character.MatchExact})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, result)
+
+	// ...but the fetch still completes and is cached for the others
+	close(release)
+	select {
+	case <-saved:
+	case <-time.After(time.Second):
+		t.Fatal("the fetch was not saved")
+	}
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetByName_RemembersMiss(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	misses := character.NewMemoryMissStore(time.Hour, 10)
+	svc := character.NewService(mockClient, mockRepo, character.WithMissStore(misses))
+	ctx := context.Background()
+
+	mockRepo.On("FindByName", mock.Anything, "Gokku", character.MatchExact).Return(nil, nil)
+	mockClient.On("SearchCharactersByName", mock.Anything, "Gokku").Return([]*dragonball.Character{}, nil).Once()
+
+	// The first lookup asks the api...
+	result, err := svc.GetByName(ctx, "Gokku", character.LookupOptions{Match: character.MatchExact})
+	assert.ErrorIs(t, err, character.ErrCharacterNotFound)
+	assert.Nil(t, result)
+
+	// ...the next ones do not
+	result, err = svc.GetByName(ctx, "Gokku", character.LookupOptions{Match: character.MatchExact})
+	assert.ErrorIs(t, err, character.ErrCharacterNotFound)
+	assert.Nil(t, result)
+
+	_, err = svc.Search(ctx, "gokku")
+	assert.ErrorIs(t, err, character.ErrCharacterNotFound)
+
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetByName_RefreshIgnoresMiss(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	misses := character.NewMemoryMissStore(time.Hour, 10)
+	svc := character.NewService(mockClient, mockRepo, character.WithMissStore(misses))
+	ctx := context.Background()
+
+	assert.NoError(t, misses.Add(ctx, "Broly"))
+
+	mockRepo.On("FindByName", mock.Anything, "Broly", character.MatchExact).Return(nil, nil)
+	mockClient.On("SearchCharactersByName", mock.Anything, "Broly").Return([]*dragonball.Character{{ID: 4, Name: "Broly"}}, nil)
+	mockRepo.On("SaveAll", mock.Anything, mock.AnythingOfType("[]*character.Character")).Return(nil)
+
+	result, err := svc.GetByName(ctx, "Broly", character.LookupOptions{Match: character.MatchExact, Refresh: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "Broly", result.Name)
+
+	// The api knows the name now
+	known, _ := misses.Has(ctx, "Broly")
+	assert.False(t, known)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_PurgeMisses(t *testing.T) {
+	misses := character.NewMemoryMissStore(time.Hour, 10)
+	svc := character.NewService(new(mock_dragonball.Client), new(mocks.Repository), character.WithMissStore(misses))
+	ctx := context.Background()
+
+	assert.NoError(t, misses.Add(ctx, "a"))
+	assert.NoError(t, misses.Add(ctx, "b"))
+	assert.NoError(t, misses.Add(ctx, "c"))
+
+	purged, err := svc.PurgeMisses(ctx, "A")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	purged, err = svc.PurgeMisses(ctx, "unknown")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), purged)
+
+	purged, err = svc.PurgeMisses(ctx, "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+}
+
+func TestService_GetByName_CountsLookupSources(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	metrics := &character.Metrics{}
+	svc := character.NewService(mockClient, mockRepo,
+		character.WithMetrics(metrics),
+		character.WithMissStore(character.NewMemoryMissStore(time.Hour, 10)),
+	)
+	ctx := context.Background()
+
+	mockRepo.On("FindByName", mock.Anything, "Goku", character.MatchExact).Return(&character.Character{ID: 1, Name: "Goku"}, nil)
+	mockRepo.On("FindByName", mock.Anything, "Unknown", character.MatchExact).Return(nil, nil)
+	mockClient.On("SearchCharactersByName", mock.Anything, "Unknown").Return([]*dragonball.Character{}, nil).Once()
+
+	_, err := svc.GetByName(ctx, "Goku", character.LookupOptions{})
+	assert.NoError(t, err)
+	_, err = svc.GetByName(ctx, "Unknown", character.LookupOptions{})
+	assert.ErrorIs(t, err, character.ErrCharacterNotFound)
+	_, err = svc.GetByName(ctx, "Unknown", character.LookupOptions{})
+	assert.ErrorIs(t, err, character.ErrCharacterNotFound)
+
+	assert.Equal(t, int64(1), metrics.DatabaseHits.Load())
+	assert.Equal(t, int64(1), metrics.CacheMisses.Load())
+	assert.Equal(t, int64(1), metrics.NegativeHits.Load())
+	assert.Equal(t, int64(0), metrics.SharedCacheHits.Load())
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetByName_AnnotatesCacheSource(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+	ctx := logging.WithAnnotations(context.Background())
+
+	mockRepo.On("FindByName", mock.Anything, "Goku", character.MatchExact).Return(&character.Character{ID: 1, Name: "Goku"}, nil)
+
+	_, err := svc.GetByName(ctx, "Goku", character.LookupOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []slog.Attr{slog.String("cache_source", "db")}, logging.Annotations(ctx))
+}
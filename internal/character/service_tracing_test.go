@@ -0,0 +1,65 @@
+package character_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/character"
+	"github.com/gclamigueiro/dragon-ball-api/internal/character/mocks"
+	"github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
+	mock_dragonball "github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball/mocks"
+	"github.com/gclamigueiro/dragon-ball-api/internal/tracing/tracingtest"
+)
+
+func TestService_GetByName_Span(t *testing.T) {
+	spans := tracingtest.Install(t)
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("FindByName", mock.Anything, "Goku", character.MatchExact).Return(&character.Character{ID: 1, Name: "Goku"}, nil)
+	mockRepo.On("FindByName", mock.Anything, "Unknown", character.MatchExact).Return(nil, nil)
+	mockClient.On("SearchCharactersByName", mock.Anything, "Unknown").Return([]*dragonball.Character{}, nil)
+
+	_, err := svc.GetByName(ctx, "Goku", character.LookupOptions{})
+	require.NoError(t, err)
+	_, err = svc.GetByName(ctx, "Unknown", character.LookupOptions{})
+	require.ErrorIs(t, err, character.ErrCharacterNotFound)
+
+	recorded := tracingtest.Find(spans, "character.GetByName")
+	require.Len(t, recorded, 2)
+
+	assert.Contains(t, recorded[0].Attributes, attribute.String("character.name", "Goku"))
+	assert.Contains(t, recorded[0].Attributes, attribute.String("source", "db"))
+	assert.Contains(t, recorded[0].Attributes, attribute.Bool("cache_hit", true))
+
+	// A name unknown to the api is an answer, not a failure
+	assert.Contains(t, recorded[1].Attributes, attribute.String("source", "upstream"))
+	assert.Contains(t, recorded[1].Attributes, attribute.Bool("cache_hit", false))
+	assert.NotEqual(t, codes.Error, recorded[1].Status.Code)
+}
+
+func TestService_GetByName_SpanRecordsErrors(t *testing.T) {
+	spans := tracingtest.Install(t)
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := character.NewService(mockClient, mockRepo)
+
+	mockRepo.On("FindByName", mock.Anything, "Goku", character.MatchExact).Return(nil, nil)
+	mockClient.On("SearchCharactersByName", mock.Anything, "Goku").Return(nil, dragonball.ErrCircuitOpen)
+
+	_, err := svc.GetByName(context.Background(), "Goku", character.LookupOptions{Match: character.MatchExact})
+	require.Error(t, err)
+
+	recorded := tracingtest.Find(spans, "character.GetByName")
+	require.Len(t, recorded, 1)
+	assert.Equal(t, codes.Error, recorded[0].Status.Code)
+	assert.NotEmpty(t, recorded[0].Events, "the error should be recorded")
+}
@@ -0,0 +1,38 @@
+package character
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Transformation is a form a character can take, e.g. "Goku SSJ"
+type Transformation struct {
+	ID          int         `gorm:"primaryKey;not null" json:"id"`
+	CharacterID int         `gorm:"not null;index" json:"character_id"`
+	Name        string      `gorm:"not null;check:name <> ''" json:"name"`
+	Ki          string      `json:"ki"`
+	KiNumeric   *PowerLevel `gorm:"type:numeric" json:"ki_numeric"` // Nil when Ki could not be parsed
+	Image       string      `json:"image"`
+	DeletedAt   *time.Time  `json:"deleted_at,omitempty"`
+}
+
+// AfterFind leaves a NULL power level as nil
+func (t *Transformation) AfterFind(tx *gorm.DB) error {
+	t.KiNumeric = nullable(t.KiNumeric)
+	return nil
+}
+
+func (t *Transformation) IsValid() bool {
+	return t.ID != 0 && t.CharacterID != 0 && t.Name != ""
+}
+
+// SameData reports whether the fetched transformation has the data already saved
+func (t *Transformation) SameData(fetched *Transformation) bool {
+	return t.CharacterID == fetched.CharacterID &&
+		t.Name == fetched.Name &&
+		t.Ki == fetched.Ki &&
+		t.KiNumeric.Equal(fetched.KiNumeric) &&
+		t.Image == fetched.Image &&
+		SameTime(t.DeletedAt, fetched.DeletedAt)
+}
@@ -0,0 +1,96 @@
+package dragonball
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the api after too many consecutive failures
+var ErrCircuitOpen = fmt.Errorf("circuit breaker is open: %w", ErrUnavailable)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// circuitBreaker fails fast after threshold consecutive failures. Once the
+// cooldown is over a single trial request is let through: if it succeeds the
+// circuit closes again, otherwise it stays open for another cooldown.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	state     breakerState
+	failures  int
+	openedAt  time.Time
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// allow reports whether a request can be made
+func (b *circuitBreaker) allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		// Only the trial request is let through
+		return false
+	default:
+		return true
+	}
+}
+
+// success closes the circuit
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = breakerClosed
+	b.failures = 0
+}
+
+// failure counts a failed request, opening the circuit when the threshold is reached
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == breakerHalfOpen || (b.threshold > 0 && b.failures >= b.threshold) {
+		b.state = breakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// release gives back the trial request when its result does not tell
+// anything about the api, e.g. the caller cancelled it
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerHalfOpen {
+		b.state = breakerOpen
+		b.openedAt = b.now().Add(-b.cooldown)
+	}
+}
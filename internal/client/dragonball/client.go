@@ -1,59 +1,353 @@
 package dragonball
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
 	"net/http"
 	"net/url"
+	"strconv"
+	"time"
 )
 
 type Client interface {
-	GetCharacterByName(name string) (*Character, error)
+	GetCharacterByName(ctx context.Context, name string) (*Character, error)
+	SearchCharactersByName(ctx context.Context, name string) ([]*Character, error)
+	GetCharacterByID(ctx context.Context, id int) (*CharacterDetail, error)
+	SearchPlanetsByName(ctx context.Context, name string) ([]*Planet, error)
+	GetPlanetByID(ctx context.Context, id int) (*PlanetDetail, error)
+	ListCharacters(ctx context.Context, page, limit int) (*CharacterPage, error)
+	ListPlanets(ctx context.Context, page, limit int) (*PlanetPage, error)
 }
 
+// Default values of the resilience options
+const (
+	DefaultTimeout          = 5 * time.Second
+	DefaultMaxRetries       = 2
+	DefaultRetryBaseDelay   = 100 * time.Millisecond
+	DefaultRetryMaxDelay    = 2 * time.Second
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
 type apiClient struct {
 	httpClient *http.Client
 	baseURL    string
+
+	timeout        time.Duration
+	maxRetries     int
+	retryBaseDelay time.Duration
+	retryMaxDelay  time.Duration
+	breaker        *circuitBreaker
+	logger         *slog.Logger
 }
 
-func NewClient(baseUrl string) Client {
-	return &apiClient{
-		httpClient: &http.Client{},
-		baseURL:    baseUrl,
+// Option configures the optional behaviour of the client
+type Option func(*apiClient)
+
+// WithTimeout limits how long a single request to the api can take.
+// Zero disables the timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *apiClient) {
+		c.timeout = timeout
 	}
 }
 
-func (c *apiClient) GetCharacterByName(name string) (*Character, error) {
-	// Encode query param
-	endpoint, err := url.Parse(c.baseURL + "/characters")
+// WithRetry sets how many times a failed request is retried, waiting an
+// exponential backoff with jitter between baseDelay and maxDelay
+func WithRetry(maxRetries int, baseDelay, maxDelay time.Duration) Option {
+	return func(c *apiClient) {
+		c.maxRetries = maxRetries
+		c.retryBaseDelay = baseDelay
+		c.retryMaxDelay = maxDelay
+	}
+}
+
+// WithCircuitBreaker fails fast with ErrCircuitOpen after threshold
+// consecutive failures, until the cooldown is over. Zero threshold disables it.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *apiClient) {
+		c.breaker = newCircuitBreaker(threshold, cooldown)
+	}
+}
+
+// WithTransport sends the requests through the transport, e.g. to trace them
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *apiClient) {
+		c.httpClient.Transport = transport
+	}
+}
+
+// WithLogger sets the logger of the requests and retries, slog.Default() if not set
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *apiClient) {
+		c.logger = logger
+	}
+}
+
+func NewClient(baseUrl string, opts ...Option) Client {
+	c := &apiClient{
+		httpClient:     &http.Client{},
+		baseURL:        baseUrl,
+		timeout:        DefaultTimeout,
+		maxRetries:     DefaultMaxRetries,
+		retryBaseDelay: DefaultRetryBaseDelay,
+		retryMaxDelay:  DefaultRetryMaxDelay,
+		breaker:        newCircuitBreaker(DefaultBreakerThreshold, DefaultBreakerCooldown),
+		logger:         slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *apiClient) GetCharacterByName(ctx context.Context, name string) (*Character, error) {
+	characters, err := c.SearchCharactersByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(characters) == 0 {
+		return nil, nil
+	}
+
+	return characters[0], nil
+}
+
+// SearchCharactersByName returns every character the api matches for the name
+func (c *apiClient) SearchCharactersByName(ctx context.Context, name string) ([]*Character, error) {
+	endpoint, err := c.searchURL("characters", name)
+	if err != nil {
+		return nil, err
+	}
+
+	var characters CharacterResponse
+	if _, err := c.getJSON(ctx, endpoint, &characters); err != nil {
+		return nil, err
+	}
+
+	return characters, nil
+}
+
+// GetCharacterByID returns the character with its transformations.
+// It returns nil if the api does not know the id.
+func (c *apiClient) GetCharacterByID(ctx context.Context, id int) (*CharacterDetail, error) {
+	endpoint, err := url.JoinPath(c.baseURL, "characters", strconv.Itoa(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base URL: %w", err)
+	}
+
+	var character CharacterDetail
+	found, err := c.getJSON(ctx, endpoint, &character)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return &character, nil
+}
+
+// SearchPlanetsByName returns every planet the api matches for the name
+func (c *apiClient) SearchPlanetsByName(ctx context.Context, name string) ([]*Planet, error) {
+	endpoint, err := c.searchURL("planets", name)
+	if err != nil {
+		return nil, err
+	}
+
+	var planets PlanetResponse
+	if _, err := c.getJSON(ctx, endpoint, &planets); err != nil {
+		return nil, err
+	}
+
+	return planets, nil
+}
+
+// GetPlanetByID returns the planet with the characters born there.
+// It returns nil if the api does not know the id.
+func (c *apiClient) GetPlanetByID(ctx context.Context, id int) (*PlanetDetail, error) {
+	endpoint, err := url.JoinPath(c.baseURL, "planets", strconv.Itoa(id))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse base URL: %w", err)
 	}
 
+	var planet PlanetDetail
+	found, err := c.getJSON(ctx, endpoint, &planet)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return &planet, nil
+}
+
+// ListCharacters returns a page of the whole catalog of characters
+func (c *apiClient) ListCharacters(ctx context.Context, page, limit int) (*CharacterPage, error) {
+	endpoint, err := c.pageURL("characters", page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var characters CharacterPage
+	if _, err := c.getJSON(ctx, endpoint, &characters); err != nil {
+		return nil, err
+	}
+
+	return &characters, nil
+}
+
+// ListPlanets returns a page of the whole catalog of planets
+func (c *apiClient) ListPlanets(ctx context.Context, page, limit int) (*PlanetPage, error) {
+	endpoint, err := c.pageURL("planets", page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var planets PlanetPage
+	if _, err := c.getJSON(ctx, endpoint, &planets); err != nil {
+		return nil, err
+	}
+
+	return &planets, nil
+}
+
+// pageURL builds the url of a page of a resource listing
+func (c *apiClient) pageURL(resource string, page, limit int) (string, error) {
+	endpoint, err := url.Parse(c.baseURL + "/" + resource)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse base URL: %w", err)
+	}
+
+	query := endpoint.Query()
+	query.Set("page", strconv.Itoa(page))
+	query.Set("limit", strconv.Itoa(limit))
+	endpoint.RawQuery = query.Encode()
+
+	return endpoint.String(), nil
+}
+
+// searchURL builds the url to search a resource by name
+func (c *apiClient) searchURL(resource, name string) (string, error) {
+	// Encode query param
+	endpoint, err := url.Parse(c.baseURL + "/" + resource)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse base URL: %w", err)
+	}
+
 	query := endpoint.Query()
 	query.Set("name", name)
 	endpoint.RawQuery = query.Encode()
 
-	fmt.Println("Requesting character by name:", name, "at", endpoint.String())
+	return endpoint.String(), nil
+}
+
+// getJSON requests the endpoint and decodes the response into out.
+// It returns false if the api answered with a 404.
+// Network errors and 5xx/429 responses are retried, and count as failures
+// for the circuit breaker. Errors unwrap to the sentinel errors of the package.
+func (c *apiClient) getJSON(ctx context.Context, endpoint string, out interface{}) (bool, error) {
+	if !c.breaker.allow() {
+		c.logger.DebugContext(ctx, "circuit breaker is open, skipping the api", "url", endpoint)
+		return false, ErrCircuitOpen
+	}
+
+	var (
+		retryable bool
+		err       error
+	)
+	for attempt := 0; ; attempt++ {
+		c.logger.DebugContext(ctx, "requesting the api", "url", endpoint, "attempt", attempt+1)
+		retryable, err = c.get(ctx, endpoint, out)
+		if err == nil || !retryable || attempt >= c.maxRetries {
+			break
+		}
+		c.logger.WarnContext(ctx, "request to the api failed, retrying",
+			"url", endpoint, "attempt", attempt+1, "error", err)
+		if waitErr := c.backoff(ctx, attempt); waitErr != nil {
+			err = waitErr
+			break
+		}
+	}
+
+	switch {
+	case err == nil:
+		c.breaker.success()
+	case ctx.Err() != nil:
+		// The caller went away, it says nothing about the api
+		c.breaker.release()
+	case retryable:
+		c.breaker.failure()
+	default:
+		// The api answered, e.g. with a 4xx
+		c.breaker.success()
+	}
 
-	resp, err := c.httpClient.Get(endpoint.String())
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// get makes a single request, telling whether the error is worth a retry
+func (c *apiClient) get(ctx context.Context, endpoint string, out interface{}) (retryable bool, err error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+		switch {
+		case errors.Is(err, context.Canceled):
+			// The caller went away, there is no point in retrying
+			return false, fmt.Errorf("failed to make request: %w", err)
+		case errors.Is(err, context.DeadlineExceeded):
+			return true, fmt.Errorf("%w: %w", ErrTimeout, err)
+		default:
+			return true, fmt.Errorf("%w: failed to make request: %w", ErrUnavailable, err)
+		}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status %d for name %q", resp.StatusCode, name)
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxBodySnippet))
+		apiErr := &APIError{StatusCode: resp.StatusCode, URL: endpoint, Body: string(snippet)}
+		return apiErr.Retryable(), apiErr
 	}
 
-	var characters CharacterResponse
-	if err := json.NewDecoder(resp.Body).Decode(&characters); err != nil {
-		return nil, fmt.Errorf("failed to decode character response: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("%w: failed to decode response: %w", ErrBadGateway, err)
 	}
 
-	if len(characters) == 0 {
-		return nil, nil
+	return false, nil
+}
+
+// backoff waits before the next attempt: an exponential delay capped at
+// retryMaxDelay, with full jitter so clients do not retry in lockstep
+func (c *apiClient) backoff(ctx context.Context, attempt int) error {
+	delay := c.retryBaseDelay << attempt
+	if delay <= 0 || delay > c.retryMaxDelay {
+		delay = c.retryMaxDelay
+	}
+	if delay > 0 {
+		delay = rand.N(delay) + 1
 	}
 
-	return characters[0], nil
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
@@ -0,0 +1,282 @@
+package dragonball_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
+)
+
+func TestClient_GetCharacterByID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/characters/1", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"id": 1, "name": "Goku", "ki": "60.000.000", "maxKi": "90 Septillion",
+			"originPlanet": {"id": 3, "name": "Vegeta", "isDestroyed": true},
+			"transformations": [{"id": 1, "name": "Goku SSJ", "ki": "3 Billion"}]
+		}`))
+	}))
+	defer server.Close()
+
+	client := dragonball.NewClient(server.URL + "/api")
+
+	detail, err := client.GetCharacterByID(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Goku", detail.Name)
+	assert.Equal(t, "90 Septillion", detail.MaxKi)
+	assert.Equal(t, 3, detail.OriginPlanet.ID)
+	assert.Len(t, detail.Transformations, 1)
+}
+
+func TestClient_GetCharacterByID_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := dragonball.NewClient(server.URL)
+
+	detail, err := client.GetCharacterByID(context.Background(), 999)
+	assert.NoError(t, err)
+	assert.Nil(t, detail)
+}
+
+func TestClient_SearchCharactersByName(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/characters", r.URL.Path)
+		assert.Equal(t, "Go", r.URL.Query().Get("name"))
+		_, _ = w.Write([]byte(`[{"id": 1, "name": "Goku"}, {"id": 5, "name": "Gohan"}]`))
+	}))
+	defer server.Close()
+
+	client := dragonball.NewClient(server.URL)
+
+	characters, err := client.SearchCharactersByName(context.Background(), "Go")
+	assert.NoError(t, err)
+	assert.Len(t, characters, 2)
+}
+
+func TestClient_ListCharacters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/characters", r.URL.Path)
+		assert.Equal(t, "2", r.URL.Query().Get("page"))
+		assert.Equal(t, "10", r.URL.Query().Get("limit"))
+		_, _ = w.Write([]byte(`{
+			"items": [{"id": 11, "name": "Gohan"}],
+			"meta": {"totalItems": 58, "itemCount": 1, "itemsPerPage": 10, "totalPages": 6, "currentPage": 2}
+		}`))
+	}))
+	defer server.Close()
+
+	client := dragonball.NewClient(server.URL)
+
+	page, err := client.ListCharacters(context.Background(), 2, 10)
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, 6, page.Meta.TotalPages)
+	assert.True(t, page.Meta.HasNext())
+}
+
+func TestClient_ListPlanets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/planets", r.URL.Path)
+		_, _ = w.Write([]byte(`{
+			"items": [{"id": 3, "name": "Vegeta", "isDestroyed": true}],
+			"meta": {"totalItems": 1, "itemCount": 1, "itemsPerPage": 10, "totalPages": 1, "currentPage": 1}
+		}`))
+	}))
+	defer server.Close()
+
+	client := dragonball.NewClient(server.URL)
+
+	page, err := client.ListPlanets(context.Background(), 1, 10)
+	assert.NoError(t, err)
+	assert.True(t, page.Items[0].IsDestroyed)
+	assert.False(t, page.Meta.HasNext())
+}
+
+func TestClient_UnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(strings.Repeat("boom", 500)))
+	}))
+	defer server.Close()
+
+	client := dragonball.NewClient(server.URL, dragonball.WithRetry(0, 0, 0))
+
+	characters, err := client.SearchCharactersByName(context.Background(), "Go")
+	assert.Nil(t, characters)
+
+	var apiErr *dragonball.APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+		assert.Contains(t, apiErr.URL, "/characters?name=Go")
+		assert.Len(t, apiErr.Body, 512)
+	}
+	assert.ErrorIs(t, err, dragonball.ErrBadGateway)
+}
+
+func TestClient_ErrorSentinels(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusTooManyRequests, dragonball.ErrRateLimited},
+		{http.StatusBadRequest, dragonball.ErrBadGateway},
+		{http.StatusBadGateway, dragonball.ErrBadGateway},
+		{http.StatusServiceUnavailable, dragonball.ErrUnavailable},
+		{http.StatusGatewayTimeout, dragonball.ErrTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			client := dragonball.NewClient(server.URL, dragonball.WithRetry(0, 0, 0))
+
+			_, err := client.SearchCharactersByName(context.Background(), "Go")
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestClient_InvalidBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html>oops</html>`))
+	}))
+	defer server.Close()
+
+	client := dragonball.NewClient(server.URL)
+
+	_, err := client.SearchCharactersByName(context.Background(), "Go")
+	assert.ErrorIs(t, err, dragonball.ErrBadGateway)
+}
+
+func TestClient_CancelledContext(t *testing.T) {
+	requested := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(requested)
+		// Hold the request until the client goes away
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := dragonball.NewClient(server.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-requested
+		cancel()
+	}()
+
+	characters, err := client.SearchCharactersByName(ctx, "Go")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, characters)
+}
+
+func TestClient_RetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id": 1, "name": "Goku"}]`))
+	}))
+	defer server.Close()
+
+	client := dragonball.NewClient(server.URL, dragonball.WithRetry(2, time.Millisecond, 5*time.Millisecond))
+
+	characters, err := client.SearchCharactersByName(context.Background(), "Goku")
+	assert.NoError(t, err)
+	assert.Len(t, characters, 1)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestClient_DoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := dragonball.NewClient(server.URL, dragonball.WithRetry(2, time.Millisecond, 5*time.Millisecond))
+
+	_, err := client.SearchCharactersByName(context.Background(), "Goku")
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestClient_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := dragonball.NewClient(server.URL,
+		dragonball.WithTimeout(20*time.Millisecond),
+		dragonball.WithRetry(0, 0, 0),
+	)
+
+	start := time.Now()
+	_, err := client.SearchCharactersByName(context.Background(), "Goku")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, err, dragonball.ErrTimeout)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	var calls atomic.Int32
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := dragonball.NewClient(server.URL,
+		dragonball.WithRetry(0, 0, 0),
+		dragonball.WithCircuitBreaker(2, 50*time.Millisecond),
+	)
+	ctx := context.Background()
+
+	// Two failures open the circuit
+	_, err := client.SearchCharactersByName(ctx, "Goku")
+	assert.Error(t, err)
+	_, err = client.SearchCharactersByName(ctx, "Goku")
+	assert.Error(t, err)
+
+	// Now it fails fast without calling the api
+	_, err = client.SearchCharactersByName(ctx, "Goku")
+	assert.ErrorIs(t, err, dragonball.ErrUnavailable)
+	assert.ErrorIs(t, err, dragonball.ErrCircuitOpen)
+	assert.Equal(t, int32(2), calls.Load())
+
+	// After the cooldown a trial request closes it again
+	healthy.Store(true)
+	time.Sleep(60 * time.Millisecond)
+	_, err = client.SearchCharactersByName(ctx, "Goku")
+	assert.NoError(t, err)
+	_, err = client.SearchCharactersByName(ctx, "Goku")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), calls.Load())
+}
@@ -0,0 +1,57 @@
+package dragonball
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	ErrNotFound    = errors.New("upstream resource not found")
+	ErrRateLimited = errors.New("upstream rate limit exceeded")
+	ErrBadGateway  = errors.New("upstream returned an invalid response")
+	ErrUnavailable = errors.New("upstream unavailable")
+	ErrTimeout     = errors.New("upstream timeout")
+)
+
+// maxBodySnippet is how much of an error response is kept for the logs
+const maxBodySnippet = 512
+
+// APIError is returned when the api answers with an unexpected status.
+// It unwraps to one of the sentinel errors depending on the status.
+type APIError struct {
+	StatusCode int
+	URL        string
+	Body       string // The beginning of the response body
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("unexpected status %d for %s: %s", e.StatusCode, e.URL, e.Body)
+}
+
+func (e *APIError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode == http.StatusServiceUnavailable:
+		return ErrUnavailable
+	case e.StatusCode == http.StatusGatewayTimeout:
+		return ErrTimeout
+	default:
+		return ErrBadGateway
+	}
+}
+
+// Retryable reports whether the same request may succeed later
+func (e *APIError) Retryable() bool {
+	return e.StatusCode >= http.StatusInternalServerError || e.StatusCode == http.StatusTooManyRequests
+}
+
+// WrapError prefixes an error of the client for the callers of the services.
+// It is kept in the chain, so the sentinels above can still be checked and
+// mapped to their problem, e.g. ErrUnavailable when the circuit is open.
+func WrapError(err error) error {
+	return fmt.Errorf("external API error: %w", err)
+}
@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	dragonball "github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,9 +14,39 @@ type Client struct {
 	mock.Mock
 }
 
-// GetCharacterByName provides a mock function with given fields: name
-func (_m *Client) GetCharacterByName(name string) (*dragonball.Character, error) {
-	ret := _m.Called(name)
+// GetCharacterByID provides a mock function with given fields: ctx, id
+func (_m *Client) GetCharacterByID(ctx context.Context, id int) (*dragonball.CharacterDetail, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCharacterByID")
+	}
+
+	var r0 *dragonball.CharacterDetail
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*dragonball.CharacterDetail, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *dragonball.CharacterDetail); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dragonball.CharacterDetail)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCharacterByName provides a mock function with given fields: ctx, name
+func (_m *Client) GetCharacterByName(ctx context.Context, name string) (*dragonball.Character, error) {
+	ret := _m.Called(ctx, name)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetCharacterByName")
@@ -22,19 +54,169 @@ func (_m *Client) GetCharacterByName(name string) (*dragonball.Character, error)
 
 	var r0 *dragonball.Character
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (*dragonball.Character, error)); ok {
-		return rf(name)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*dragonball.Character, error)); ok {
+		return rf(ctx, name)
 	}
-	if rf, ok := ret.Get(0).(func(string) *dragonball.Character); ok {
-		r0 = rf(name)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *dragonball.Character); ok {
+		r0 = rf(ctx, name)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*dragonball.Character)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(name)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPlanetByID provides a mock function with given fields: ctx, id
+func (_m *Client) GetPlanetByID(ctx context.Context, id int) (*dragonball.PlanetDetail, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPlanetByID")
+	}
+
+	var r0 *dragonball.PlanetDetail
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*dragonball.PlanetDetail, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *dragonball.PlanetDetail); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dragonball.PlanetDetail)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListCharacters provides a mock function with given fields: ctx, page, limit
+func (_m *Client) ListCharacters(ctx context.Context, page int, limit int) (*dragonball.CharacterPage, error) {
+	ret := _m.Called(ctx, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListCharacters")
+	}
+
+	var r0 *dragonball.CharacterPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (*dragonball.CharacterPage, error)); ok {
+		return rf(ctx, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) *dragonball.CharacterPage); ok {
+		r0 = rf(ctx, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dragonball.CharacterPage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListPlanets provides a mock function with given fields: ctx, page, limit
+func (_m *Client) ListPlanets(ctx context.Context, page int, limit int) (*dragonball.PlanetPage, error) {
+	ret := _m.Called(ctx, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListPlanets")
+	}
+
+	var r0 *dragonball.PlanetPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (*dragonball.PlanetPage, error)); ok {
+		return rf(ctx, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) *dragonball.PlanetPage); ok {
+		r0 = rf(ctx, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dragonball.PlanetPage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SearchCharactersByName provides a mock function with given fields: ctx, name
+func (_m *Client) SearchCharactersByName(ctx context.Context, name string) ([]*dragonball.Character, error) {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchCharactersByName")
+	}
+
+	var r0 []*dragonball.Character
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*dragonball.Character, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*dragonball.Character); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*dragonball.Character)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SearchPlanetsByName provides a mock function with given fields: ctx, name
+func (_m *Client) SearchPlanetsByName(ctx context.Context, name string) ([]*dragonball.Planet, error) {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchPlanetsByName")
+	}
+
+	var r0 []*dragonball.Planet
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*dragonball.Planet, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*dragonball.Planet); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*dragonball.Planet)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1,10 +1,76 @@
 package dragonball
 
+import "time"
+
 type CharacterResponse []*Character
 
 type Character struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
-	Ki   string `json:"ki"`
-	Race string `json:"race"`
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Ki          string     `json:"ki"`
+	MaxKi       string     `json:"maxKi"`
+	Race        string     `json:"race"`
+	Gender      string     `json:"gender"`
+	Description string     `json:"description"`
+	Image       string     `json:"image"`
+	Affiliation string     `json:"affiliation"`
+	DeletedAt   *time.Time `json:"deletedAt"`
+}
+
+// CharacterDetail is the response of /characters/{id}
+type CharacterDetail struct {
+	Character
+	OriginPlanet    *Planet           `json:"originPlanet"`
+	Transformations []*Transformation `json:"transformations"`
+}
+
+type Transformation struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Image     string     `json:"image"`
+	Ki        string     `json:"ki"`
+	DeletedAt *time.Time `json:"deletedAt"`
+}
+
+type PlanetResponse []*Planet
+
+type Planet struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	IsDestroyed bool       `json:"isDestroyed"`
+	Description string     `json:"description"`
+	Image       string     `json:"image"`
+	DeletedAt   *time.Time `json:"deletedAt"`
+}
+
+// PlanetDetail is the response of /planets/{id}
+type PlanetDetail struct {
+	Planet
+	Characters []*Character `json:"characters"`
+}
+
+// PageMeta describes a page of a paginated listing
+type PageMeta struct {
+	TotalItems   int `json:"totalItems"`
+	ItemCount    int `json:"itemCount"`
+	ItemsPerPage int `json:"itemsPerPage"`
+	TotalPages   int `json:"totalPages"`
+	CurrentPage  int `json:"currentPage"`
+}
+
+// HasNext reports whether there are pages after this one
+func (m PageMeta) HasNext() bool {
+	return m.CurrentPage < m.TotalPages
+}
+
+// CharacterPage is the response of /characters?page=&limit=
+type CharacterPage struct {
+	Items []*Character `json:"items"`
+	Meta  PageMeta     `json:"meta"`
+}
+
+// PlanetPage is the response of /planets?page=&limit=
+type PlanetPage struct {
+	Items []*Planet `json:"items"`
+	Meta  PageMeta  `json:"meta"`
 }
@@ -2,7 +2,10 @@ package config
 
 import (
 	"log"
+	"log/slog"
 	"os"
+	"strconv"
+	"time"
 )
 
 // Config holds all configuration values from environment
@@ -15,8 +18,77 @@ type Config struct {
 	DBName     string
 
 	DBAPIBaseURL string
+
+	LogFormat          string        // Format of the logs: "text" or "json"
+	LogLevel           slog.Level    // Minimum level of the logs
+	SlowQueryThreshold time.Duration // Queries slower than this are logged as warnings, 0 disables it
+
+	HTTPReadTimeout       time.Duration // Max duration to read a whole request
+	HTTPReadHeaderTimeout time.Duration // Max duration to read the request headers
+	HTTPWriteTimeout      time.Duration // Max duration to write a response, it must cover the retries to the api
+	HTTPIdleTimeout       time.Duration // How long a keep-alive connection waits for the next request
+	HTTPMaxHeaderBytes    int           // Max size of the request headers
+	ShutdownTimeout       time.Duration // How long the in-flight requests are waited for on shutdown
+
+	HealthCheckTimeout time.Duration // Max duration of each dependency check of /readyz
+	HealthUpstreamTTL  time.Duration // How long the probe of the api is reused by /readyz, 0 disables the probe
+
+	TracingExporter string // Where the spans are sent: "none", "otlp" or "stdout"
+	ServiceName     string // Name of the service in the spans
+
+	CacheTTL time.Duration // How long a cached character is served before fetching it again
+
+	CacheBackend  string // Cache shared with the other replicas: "none", "memory" or "redis"
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+
+	RepositoryCacheSize int           // Lookups kept in the in-process cache in front of the database, 0 disables it
+	RepositoryCacheTTL  time.Duration // How long a lookup is kept in the in-process cache
+
+	NegativeCacheTTL     time.Duration // How long a name unknown to the api is answered as not found, 0 disables it
+	NegativeCachePersist bool          // Keep the unknown names in the database instead of in memory
+
+	AdminToken string // Bearer token of the /admin routes, they are disabled if empty
+
+	RefreshInterval    time.Duration // How often the saved characters are fetched again in the background, 0 disables it
+	RefreshConcurrency int           // Characters fetched at the same time by the background refresh
+	RefreshRate        float64       // Requests per second of the background refresh, 0 means no limit
+	RefreshBatchSize   int           // Characters checked on each background refresh
+
+	UpstreamTimeout          time.Duration // Max duration of a single request to the api
+	UpstreamMaxRetries       int           // Retries of a failed request to the api
+	UpstreamRetryBaseDelay   time.Duration // First backoff between retries, it doubles on each retry
+	UpstreamRetryMaxDelay    time.Duration // Max backoff between retries
+	UpstreamBreakerThreshold int           // Consecutive failures that open the circuit breaker, 0 disables it
+	UpstreamBreakerCooldown  time.Duration // How long the circuit stays open before trying again
 }
 
+// Default values of the optional environment variables
+const (
+	defaultSlowQueryThreshold       = 200 * time.Millisecond
+	defaultHTTPReadTimeout          = 10 * time.Second
+	defaultHTTPReadHeaderTimeout    = 5 * time.Second
+	defaultHTTPWriteTimeout         = 30 * time.Second
+	defaultHTTPIdleTimeout          = 60 * time.Second
+	defaultHTTPMaxHeaderBytes       = 64 << 10
+	defaultShutdownTimeout          = 15 * time.Second
+	defaultHealthCheckTimeout       = 2 * time.Second
+	defaultServiceName              = "dragon-ball-api"
+	defaultCacheTTL                 = 24 * time.Hour
+	defaultRepositoryCacheTTL       = time.Minute
+	defaultNegativeCacheTTL         = time.Hour
+	defaultRefreshConcurrency       = 4
+	defaultRefreshRate              = 2
+	defaultRefreshBatchSize         = 100
+	defaultUpstreamTimeout          = 5 * time.Second
+	defaultUpstreamMaxRetries       = 2
+	defaultUpstreamRetryBaseDelay   = 100 * time.Millisecond
+	defaultUpstreamRetryMaxDelay    = 2 * time.Second
+	defaultUpstreamBreakerThreshold = 5
+	defaultUpstreamBreakerCooldown  = 30 * time.Second
+)
+
 // LoadConfig loads environment variables into the Config struct
 func LoadConfig() *Config {
 	requiredEnv := []string{
@@ -29,7 +101,7 @@ func LoadConfig() *Config {
 		}
 	}
 
-	return &Config{
+	cfg := &Config{
 		APIPort:      os.Getenv("API_PORT"),
 		DBHost:       os.Getenv("DB_HOST"),
 		DBPort:       os.Getenv("DB_PORT"),
@@ -37,5 +109,150 @@ func LoadConfig() *Config {
 		DBPassword:   os.Getenv("DB_PASSWORD"),
 		DBName:       os.Getenv("DB_NAME"),
 		DBAPIBaseURL: os.Getenv("DB_API_BASE_URL"),
+
+		LogFormat:          getOneOf("LOG_FORMAT", "text", "json"),
+		LogLevel:           getLevel("LOG_LEVEL", slog.LevelInfo),
+		SlowQueryThreshold: getDuration("DB_SLOW_QUERY_THRESHOLD", defaultSlowQueryThreshold),
+
+		HTTPReadTimeout:       getDuration("HTTP_READ_TIMEOUT", defaultHTTPReadTimeout),
+		HTTPReadHeaderTimeout: getDuration("HTTP_READ_HEADER_TIMEOUT", defaultHTTPReadHeaderTimeout),
+		HTTPWriteTimeout:      getDuration("HTTP_WRITE_TIMEOUT", defaultHTTPWriteTimeout),
+		HTTPIdleTimeout:       getDuration("HTTP_IDLE_TIMEOUT", defaultHTTPIdleTimeout),
+		HTTPMaxHeaderBytes:    getInt("HTTP_MAX_HEADER_BYTES", defaultHTTPMaxHeaderBytes),
+		ShutdownTimeout:       getDuration("SHUTDOWN_TIMEOUT", defaultShutdownTimeout),
+
+		HealthCheckTimeout: getDuration("HEALTH_CHECK_TIMEOUT", defaultHealthCheckTimeout),
+		HealthUpstreamTTL:  getDuration("HEALTH_UPSTREAM_TTL", 0),
+
+		TracingExporter: getOneOf("TRACING_EXPORTER", "none", "otlp", "stdout"),
+		ServiceName:     getString("OTEL_SERVICE_NAME", defaultServiceName),
+
+		CacheTTL: getDuration("CACHE_TTL", defaultCacheTTL),
+
+		CacheBackend:  getOneOf("CACHE_BACKEND", "none", "memory", "redis"),
+		RedisAddr:     os.Getenv("REDIS_ADDR"),
+		RedisPassword: os.Getenv("REDIS_PASSWORD"),
+		RedisDB:       getInt("REDIS_DB", 0),
+
+		RepositoryCacheSize: getInt("REPOSITORY_CACHE_SIZE", 0),
+		RepositoryCacheTTL:  getDuration("REPOSITORY_CACHE_TTL", defaultRepositoryCacheTTL),
+
+		NegativeCacheTTL:     getDuration("NEGATIVE_CACHE_TTL", defaultNegativeCacheTTL),
+		NegativeCachePersist: getBool("NEGATIVE_CACHE_PERSIST", false),
+
+		AdminToken: os.Getenv("ADMIN_TOKEN"),
+
+		RefreshInterval:    getDuration("REFRESH_INTERVAL", 0),
+		RefreshConcurrency: getInt("REFRESH_CONCURRENCY", defaultRefreshConcurrency),
+		RefreshRate:        getFloat("REFRESH_RATE", defaultRefreshRate),
+		RefreshBatchSize:   getInt("REFRESH_BATCH_SIZE", defaultRefreshBatchSize),
+
+		UpstreamTimeout:          getDuration("UPSTREAM_TIMEOUT", defaultUpstreamTimeout),
+		UpstreamMaxRetries:       getInt("UPSTREAM_MAX_RETRIES", defaultUpstreamMaxRetries),
+		UpstreamRetryBaseDelay:   getDuration("UPSTREAM_RETRY_BASE_DELAY", defaultUpstreamRetryBaseDelay),
+		UpstreamRetryMaxDelay:    getDuration("UPSTREAM_RETRY_MAX_DELAY", defaultUpstreamRetryMaxDelay),
+		UpstreamBreakerThreshold: getInt("UPSTREAM_BREAKER_THRESHOLD", defaultUpstreamBreakerThreshold),
+		UpstreamBreakerCooldown:  getDuration("UPSTREAM_BREAKER_COOLDOWN", defaultUpstreamBreakerCooldown),
+	}
+
+	if cfg.CacheBackend == "redis" && cfg.RedisAddr == "" {
+		log.Fatalf("Missing required environment variable: REDIS_ADDR")
+	}
+
+	return cfg
+}
+
+// getString reads an optional value
+func getString(env, fallback string) string {
+	if value := os.Getenv(env); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getOneOf reads an optional value that must be one of the allowed ones,
+// the first one is the default
+func getOneOf(env string, allowed ...string) string {
+	value := os.Getenv(env)
+	if value == "" {
+		return allowed[0]
+	}
+
+	for _, a := range allowed {
+		if value == a {
+			return value
+		}
+	}
+	log.Fatalf("Invalid value for environment variable %s: %q, expected one of %v", env, value, allowed)
+	return ""
+}
+
+// getLevel reads an optional log level: debug, info, warn or error
+func getLevel(env string, fallback slog.Level) slog.Level {
+	value := os.Getenv(env)
+	if value == "" {
+		return fallback
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		log.Fatalf("Invalid log level for environment variable %s: %q", env, value)
+	}
+	return level
+}
+
+// getDuration reads an optional duration, e.g. "24h" or "30m"
+func getDuration(env string, fallback time.Duration) time.Duration {
+	value := os.Getenv(env)
+	if value == "" {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("Invalid duration for environment variable %s: %v", env, err)
+	}
+	return duration
+}
+
+// getInt reads an optional non-negative integer
+func getInt(env string, fallback int) int {
+	value := os.Getenv(env)
+	if value == "" {
+		return fallback
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		log.Fatalf("Invalid number for environment variable %s: %q", env, value)
+	}
+	return number
+}
+
+// getFloat reads an optional non-negative number, e.g. "0.5"
+func getFloat(env string, fallback float64) float64 {
+	value := os.Getenv(env)
+	if value == "" {
+		return fallback
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 {
+		log.Fatalf("Invalid number for environment variable %s: %q", env, value)
+	}
+	return number
+}
+
+// getBool reads an optional boolean, e.g. "true" or "0"
+func getBool(env string, fallback bool) bool {
+	value := os.Getenv(env)
+	if value == "" {
+		return fallback
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Fatalf("Invalid boolean for environment variable %s: %q", env, value)
 	}
+	return b
 }
@@ -1,11 +1,19 @@
 package db
 
+import (
+	"log/slog"
+	"time"
+)
+
 type Config struct {
 	Host     string
 	Port     string
 	User     string
 	Password string
 	Name     string
+
+	Logger             *slog.Logger  // Logger of the queries, slog.Default() if nil
+	SlowQueryThreshold time.Duration // Queries slower than this are logged as warnings, 0 disables it
 }
 
 func NewConfig(host, port, user, password, name string) *Config {
@@ -15,7 +15,9 @@ func Connect(cfg Config) *gorm.DB {
 		cfg.Host, cfg.User, cfg.Password, cfg.Name, cfg.Port,
 	)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: NewLogger(cfg.Logger, cfg.SlowQueryThreshold),
+	})
 	if err != nil {
 		log.Fatalf("failed to connect to database: %v", err)
 	}
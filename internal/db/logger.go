@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// DefaultSlowQueryThreshold is the duration from which a query is logged as slow
+const DefaultSlowQueryThreshold = 200 * time.Millisecond
+
+// unfilledPlaceholder is how GORM leaves a placeholder without its value, "$1$"
+var unfilledPlaceholder = regexp.MustCompile(`\$(\d+)\$`)
+
+// queryLogger logs the queries of the repositories: the failed ones as errors,
+// the slow ones as warnings and every other one at debug level.
+// The values are never logged, only their placeholders.
+type queryLogger struct {
+	logger        *slog.Logger
+	slowThreshold time.Duration
+}
+
+// NewLogger creates a GORM logger writing to the logger, slog.Default() if nil.
+// Zero slowThreshold does not log slow queries.
+func NewLogger(logger *slog.Logger, slowThreshold time.Duration) gormlogger.Interface {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &queryLogger{logger: logger, slowThreshold: slowThreshold}
+}
+
+// WithLogger returns a session of the connection logging its queries to the
+// logger, e.g. one tagged with the repository. The slow query threshold of
+// the connection is kept.
+func WithLogger(conn *gorm.DB, logger *slog.Logger) *gorm.DB {
+	threshold := DefaultSlowQueryThreshold
+	if current, ok := conn.Logger.(*queryLogger); ok {
+		threshold = current.slowThreshold
+	}
+	return conn.Session(&gorm.Session{Logger: NewLogger(logger, threshold)})
+}
+
+// LogMode is ignored, the level of the slog logger filters the records
+func (l *queryLogger) LogMode(gormlogger.LogLevel) gormlogger.Interface {
+	return l
+}
+
+func (l *queryLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	l.logger.InfoContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (l *queryLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	l.logger.WarnContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (l *queryLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	l.logger.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (l *queryLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+	slow := l.slowThreshold > 0 && elapsed > l.slowThreshold
+
+	level := slog.LevelDebug
+	msg := "query"
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		level, msg = slog.LevelError, "query failed"
+	case slow:
+		level, msg = slog.LevelWarn, "slow query"
+	}
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []slog.Attr{
+		slog.String("sql", unfilledPlaceholder.ReplaceAllString(sql, "$$$1")),
+		slog.Int64("rows", rows),
+		slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+	}
+	if level == slog.LevelError {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	l.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// ParamsFilter keeps the values out of the logged queries
+func (l *queryLogger) ParamsFilter(_ context.Context, sql string, _ ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
@@ -0,0 +1,92 @@
+package db_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/character"
+	"github.com/gclamigueiro/dragon-ball-api/internal/db"
+	"github.com/gclamigueiro/dragon-ball-api/internal/logging"
+)
+
+// lastRecord decodes the last record logged in JSON
+func lastRecord(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(lines[len(lines)-1], &record))
+	return record
+}
+
+func TestLogger_QueriesWithoutValues(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New(&buf, logging.FormatJSON, slog.LevelDebug)
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 db.NewLogger(logger, time.Second),
+	})
+	require.NoError(t, err)
+
+	ctx := logging.WithRequestID(context.Background(), "abc-123")
+	_, _ = character.NewStorage(gormDB).FindByName(ctx, "Goku", character.MatchExact)
+
+	record := lastRecord(t, &buf)
+	assert.Equal(t, "DEBUG", record["level"])
+	assert.Equal(t, "abc-123", record["request_id"])
+	assert.Contains(t, record["sql"], "LOWER(name) LIKE LOWER($1)")
+	assert.NotContains(t, record["sql"], "Goku")
+}
+
+func TestWithLogger_TagsTheRepositoryQueries(t *testing.T) {
+	var connection, repository bytes.Buffer
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 db.NewLogger(logging.New(&connection, logging.FormatJSON, slog.LevelDebug), 10*time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	logger := logging.New(&repository, logging.FormatJSON, slog.LevelDebug).With("repository", "characters")
+	storage := character.NewStorage(gormDB, character.WithStorageLogger(logger))
+	_, _ = storage.FindByName(context.Background(), "Goku", character.MatchExact)
+
+	assert.Empty(t, connection.String())
+	record := lastRecord(t, &repository)
+	assert.Equal(t, "characters", record["repository"])
+	assert.Contains(t, record["sql"], "LOWER(name) LIKE LOWER($1)")
+}
+
+func TestLogger_Levels(t *testing.T) {
+	var buf bytes.Buffer
+	queries := db.NewLogger(logging.New(&buf, logging.FormatJSON, slog.LevelInfo), 10*time.Millisecond)
+	ctx := context.Background()
+	sql := func() (string, int64) { return "SELECT 1", 1 }
+
+	// Fast queries and records not found are only logged at debug level
+	queries.Trace(ctx, time.Now(), sql, nil)
+	queries.Trace(ctx, time.Now(), sql, gorm.ErrRecordNotFound)
+	assert.Empty(t, buf.String())
+
+	queries.Trace(ctx, time.Now().Add(-time.Second), sql, nil)
+	record := lastRecord(t, &buf)
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "slow query", record["msg"])
+
+	queries.Trace(ctx, time.Now(), sql, errors.New("connection refused"))
+	record = lastRecord(t, &buf)
+	assert.Equal(t, "ERROR", record["level"])
+	assert.Equal(t, "connection refused", record["error"])
+}
@@ -0,0 +1,185 @@
+// Package health exposes the liveness and readiness probes of the api
+package health
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultTimeout is how long a single check can take
+const DefaultTimeout = 2 * time.Second
+
+// Status of the api or of a dependency
+const (
+	StatusOK          = "ok"
+	StatusDown        = "down"
+	StatusDegraded    = "degraded"    // An optional dependency is down, the api still answers
+	StatusUnavailable = "unavailable" // A required dependency is down
+)
+
+// Check probes a dependency, returning an error if it cannot be used
+type Check func(ctx context.Context) error
+
+// Result is the outcome of a check
+type Result struct {
+	Status    string    `json:"status"`
+	Required  bool      `json:"required"`
+	LatencyMS float64   `json:"latency_ms"`
+	Cached    bool      `json:"cached,omitempty"` // The result comes from a previous probe
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report is the body of the readiness probe
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+type dependency struct {
+	name     string
+	check    Check
+	required bool
+	ttl      time.Duration // Zero probes on every request
+
+	mu   sync.Mutex
+	last *Result
+}
+
+type Handler struct {
+	timeout      time.Duration
+	dependencies []*dependency
+	logger       *slog.Logger
+}
+
+// Option configures the optional behaviour of the handler
+type Option func(*Handler)
+
+// WithTimeout limits how long a single check can take
+func WithTimeout(timeout time.Duration) Option {
+	return func(h *Handler) {
+		h.timeout = timeout
+	}
+}
+
+// WithCheck adds a required dependency, the api is not ready while it is down
+func WithCheck(name string, check Check) Option {
+	return func(h *Handler) {
+		h.dependencies = append(h.dependencies, &dependency{name: name, check: check, required: true})
+	}
+}
+
+// WithOptionalCheck adds a dependency the api can answer without, it only
+// degrades the status. The result is reused for the ttl, so an external
+// service is not probed on every request.
+func WithOptionalCheck(name string, check Check, ttl time.Duration) Option {
+	return func(h *Handler) {
+		h.dependencies = append(h.dependencies, &dependency{name: name, check: check, ttl: ttl})
+	}
+}
+
+// WithLogger sets the logger of the failed checks, slog.Default() if not set
+func WithLogger(logger *slog.Logger) Option {
+	return func(h *Handler) {
+		h.logger = logger
+	}
+}
+
+func NewHandler(opts ...Option) *Handler {
+	h := &Handler{timeout: DefaultTimeout, logger: slog.Default()}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+func (h *Handler) RegisterRoutes(r gin.IRouter) {
+	r.GET("/healthz", h.Live) // GET /healthz
+	r.GET("/readyz", h.Ready) // GET /readyz
+}
+
+// Live handles GET /healthz, it only tells the process is answering
+func (h *Handler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": StatusOK})
+}
+
+// Ready handles GET /readyz. It answers 503 while a required dependency is
+// down, so the orchestrator stops routing requests to this instance.
+func (h *Handler) Ready(c *gin.Context) {
+	report := h.Check(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status == StatusUnavailable {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+// Check runs every check at the same time
+func (h *Handler) Check(ctx context.Context) Report {
+	results := make([]Result, len(h.dependencies))
+
+	var wg sync.WaitGroup
+	for i, dep := range h.dependencies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = dep.run(ctx, h.timeout, h.logger)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(results))}
+	for i, result := range results {
+		report.Checks[h.dependencies[i].name] = result
+		if result.Status == StatusOK {
+			continue
+		}
+		if result.Required {
+			report.Status = StatusUnavailable
+		} else if report.Status == StatusOK {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// run probes the dependency, or reuses the last result while it is fresh.
+// The errors are only logged, they can contain internal addresses.
+func (d *dependency) run(ctx context.Context, timeout time.Duration, logger *slog.Logger) Result {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.last != nil && time.Since(d.last.CheckedAt) < d.ttl {
+		cached := *d.last
+		cached.Cached = true
+		return cached
+	}
+
+	if d.ttl > 0 {
+		// The result is shared, the caller going away must not make it fail
+		ctx = context.WithoutCancel(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := d.check(ctx)
+	result := Result{
+		Status:    StatusOK,
+		Required:  d.required,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+		CheckedAt: start,
+	}
+	if err != nil {
+		logger.WarnContext(ctx, "health: dependency is down", "dependency", d.name, "error", err)
+		result.Status = StatusDown
+	}
+
+	d.last = &result
+	return result
+}
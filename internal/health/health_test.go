@@ -0,0 +1,112 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/health"
+)
+
+func ok(context.Context) error { return nil }
+
+func down(context.Context) error { return errors.New("dial tcp 10.0.0.1:5432: connection refused") }
+
+func get(handler *health.Handler, path string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	handler.RegisterRoutes(r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestLive(t *testing.T) {
+	// The process is alive even if its dependencies are down
+	w := get(health.NewHandler(health.WithCheck("database", down)), "/healthz")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status": "ok"}`, w.Body.String())
+}
+
+func TestReady(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           []health.Option
+		expectedCode   int
+		expectedStatus string
+	}{
+		{
+			name:           "all up",
+			opts:           []health.Option{health.WithCheck("database", ok), health.WithOptionalCheck("upstream", ok, 0)},
+			expectedCode:   http.StatusOK,
+			expectedStatus: health.StatusOK,
+		},
+		{
+			name:           "optional dependency down",
+			opts:           []health.Option{health.WithCheck("database", ok), health.WithOptionalCheck("upstream", down, 0)},
+			expectedCode:   http.StatusOK,
+			expectedStatus: health.StatusDegraded,
+		},
+		{
+			name:           "required dependency down",
+			opts:           []health.Option{health.WithCheck("database", down), health.WithOptionalCheck("upstream", ok, 0)},
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedStatus: health.StatusUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(health.NewHandler(tt.opts...), "/readyz")
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			var report health.Report
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+			assert.Equal(t, tt.expectedStatus, report.Status)
+			assert.Len(t, report.Checks, 2)
+			assert.True(t, report.Checks["database"].Required)
+			assert.False(t, report.Checks["upstream"].Required)
+			assert.NotContains(t, w.Body.String(), "10.0.0.1", "errors must not be exposed")
+		})
+	}
+}
+
+func TestReady_Timeout(t *testing.T) {
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	handler := health.NewHandler(health.WithTimeout(20*time.Millisecond), health.WithCheck("database", slow))
+
+	start := time.Now()
+	report := handler.Check(context.Background())
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, health.StatusDown, report.Checks["database"].Status)
+	assert.GreaterOrEqual(t, report.Checks["database"].LatencyMS, float64(20))
+}
+
+func TestReady_CachesOptionalChecks(t *testing.T) {
+	calls := 0
+	probe := func(context.Context) error {
+		calls++
+		return nil
+	}
+	handler := health.NewHandler(health.WithOptionalCheck("upstream", probe, time.Minute))
+
+	first := handler.Check(context.Background())
+	second := handler.Check(context.Background())
+
+	assert.Equal(t, 1, calls)
+	assert.False(t, first.Checks["upstream"].Cached)
+	assert.True(t, second.Checks["upstream"].Cached)
+}
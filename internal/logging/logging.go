@@ -0,0 +1,104 @@
+// Package logging creates the structured logger of the api and carries the
+// request attributes in the context, so every log of a request has its ID
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// Formats of the logs
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// New creates a logger writing in the format, text or json, the records of
+// the level and above. The ID of the request in the context is added to them.
+func New(w io.Writer, format string, level slog.Level) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch format {
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		handler = slog.NewTextHandler(w, opts)
+	}
+	return slog.New(&contextHandler{handler})
+}
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	annotationsKey
+)
+
+// WithRequestID returns a context carrying the ID of the request
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the ID of the request, or an empty string if there is none
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// annotations are the attributes added to a request while it is handled
+type annotations struct {
+	mu    sync.Mutex
+	attrs []slog.Attr
+}
+
+// WithAnnotations returns a context where Annotate can add attributes,
+// read back with Annotations once the request is handled
+func WithAnnotations(ctx context.Context) context.Context {
+	return context.WithValue(ctx, annotationsKey, &annotations{})
+}
+
+// Annotate adds the attributes to the access log of the request.
+// It does nothing if the context does not come from WithAnnotations.
+func Annotate(ctx context.Context, attrs ...slog.Attr) {
+	a, ok := ctx.Value(annotationsKey).(*annotations)
+	if !ok {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.attrs = append(a.attrs, attrs...)
+}
+
+// Annotations returns the attributes added with Annotate
+func Annotations(ctx context.Context) []slog.Attr {
+	a, ok := ctx.Value(annotationsKey).(*annotations)
+	if !ok {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]slog.Attr(nil), a.attrs...)
+}
+
+// contextHandler adds the request ID of the context to the records
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{h.Handler.WithGroup(name)}
+}
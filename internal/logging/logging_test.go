@@ -0,0 +1,49 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/logging"
+)
+
+func TestNew_JSONWithRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New(&buf, logging.FormatJSON, slog.LevelInfo).With("component", "test")
+
+	ctx := logging.WithRequestID(context.Background(), "abc-123")
+	logger.DebugContext(ctx, "hidden")
+	logger.InfoContext(ctx, "shown", "name", "Goku")
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record), "only the info record should be written")
+	assert.Equal(t, "shown", record["msg"])
+	assert.Equal(t, "abc-123", record["request_id"])
+	assert.Equal(t, "Goku", record["name"])
+	assert.Equal(t, "test", record["component"])
+}
+
+func TestNew_Text(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New(&buf, logging.FormatText, slog.LevelDebug)
+
+	logger.Debug("no request")
+	assert.Contains(t, buf.String(), "msg=\"no request\"")
+	assert.NotContains(t, buf.String(), "request_id")
+}
+
+func TestAnnotate(t *testing.T) {
+	// Without WithAnnotations there is nowhere to add them
+	logging.Annotate(context.Background(), slog.String("ignored", "yes"))
+	assert.Empty(t, logging.Annotations(context.Background()))
+
+	ctx := logging.WithAnnotations(context.Background())
+	logging.Annotate(context.WithValue(ctx, struct{}{}, "child"), slog.String("cache_source", "db"))
+	assert.Equal(t, []slog.Attr{slog.String("cache_source", "db")}, logging.Annotations(ctx))
+}
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/character"
+)
+
+// RegisterCharacterService exports the counters of the character service
+func (m *Metrics) RegisterCharacterService(metrics *character.Metrics) error {
+	counter := func(name, help string, labels prometheus.Labels, value *atomic.Int64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        name,
+			Help:        help,
+			ConstLabels: labels,
+		}, func() float64 {
+			return float64(value.Load())
+		})
+	}
+
+	lookupsHelp := "Lookups of characters by name by where they were answered from. A miss asks the Dragon Ball API."
+	lookups := func(source, result string, value *atomic.Int64) prometheus.Collector {
+		return counter("character_lookups_total", lookupsHelp, prometheus.Labels{"source": source, "result": result}, value)
+	}
+
+	for _, collector := range []prometheus.Collector{
+		lookups("shared_cache", "hit", &metrics.SharedCacheHits),
+		lookups("database", "hit", &metrics.DatabaseHits),
+		lookups("negative_cache", "hit", &metrics.NegativeHits),
+		lookups("upstream", "miss", &metrics.CacheMisses),
+		counter("character_upstream_searches_total", "Searches by name sent to the Dragon Ball API.", nil, &metrics.UpstreamSearches),
+		counter("character_coalesced_calls_total", "Searches by name that waited for the same search already in flight.", nil, &metrics.CoalescedCalls),
+	} {
+		if err := m.registry.Register(collector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterRepositoryCache exports the counters of the cache in front of the
+// character repository
+func (m *Metrics) RegisterRepositoryCache(repository *character.CachedRepository) error {
+	return m.registry.Register(&repositoryCacheCollector{repository: repository})
+}
+
+var (
+	repositoryCacheHits = prometheus.NewDesc(prometheus.BuildFQName(namespace, "repository_cache", "hits_total"),
+		"Character lookups answered by the repository cache.", nil, nil)
+	repositoryCacheMisses = prometheus.NewDesc(prometheus.BuildFQName(namespace, "repository_cache", "misses_total"),
+		"Character lookups the repository cache sent to the database.", nil, nil)
+	repositoryCacheEvictions = prometheus.NewDesc(prometheus.BuildFQName(namespace, "repository_cache", "evictions_total"),
+		"Entries dropped from the repository cache to make room for new ones.", nil, nil)
+	repositoryCacheInvalidations = prometheus.NewDesc(prometheus.BuildFQName(namespace, "repository_cache", "invalidations_total"),
+		"Entries dropped from the repository cache because a character was saved.", nil, nil)
+	repositoryCacheEntries = prometheus.NewDesc(prometheus.BuildFQName(namespace, "repository_cache", "entries"),
+		"Entries in the repository cache.", nil, nil)
+)
+
+// repositoryCacheCollector reads a snapshot of the stats on every scrape
+type repositoryCacheCollector struct {
+	repository *character.CachedRepository
+}
+
+func (c *repositoryCacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- repositoryCacheHits
+	ch <- repositoryCacheMisses
+	ch <- repositoryCacheEvictions
+	ch <- repositoryCacheInvalidations
+	ch <- repositoryCacheEntries
+}
+
+func (c *repositoryCacheCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.repository.Stats()
+	ch <- prometheus.MustNewConstMetric(repositoryCacheHits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(repositoryCacheMisses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(repositoryCacheEvictions, prometheus.CounterValue, float64(stats.Evictions))
+	ch <- prometheus.MustNewConstMetric(repositoryCacheInvalidations, prometheus.CounterValue, float64(stats.Invalidations))
+	ch <- prometheus.MustNewConstMetric(repositoryCacheEntries, prometheus.GaugeValue, float64(stats.Entries))
+}
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
+)
+
+// instrumentedClient observes the calls to another client
+type instrumentedClient struct {
+	next    dragonball.Client
+	metrics *Metrics
+}
+
+// InstrumentClient observes the count and the duration of every call of the client
+func (m *Metrics) InstrumentClient(client dragonball.Client) dragonball.Client {
+	return &instrumentedClient{next: client, metrics: m}
+}
+
+func (c *instrumentedClient) GetCharacterByName(ctx context.Context, name string) (_ *dragonball.Character, err error) {
+	defer c.observe("get_character_by_name", time.Now(), &err)
+	return c.next.GetCharacterByName(ctx, name)
+}
+
+func (c *instrumentedClient) SearchCharactersByName(ctx context.Context, name string) (_ []*dragonball.Character, err error) {
+	defer c.observe("search_characters", time.Now(), &err)
+	return c.next.SearchCharactersByName(ctx, name)
+}
+
+func (c *instrumentedClient) GetCharacterByID(ctx context.Context, id int) (_ *dragonball.CharacterDetail, err error) {
+	defer c.observe("get_character", time.Now(), &err)
+	return c.next.GetCharacterByID(ctx, id)
+}
+
+func (c *instrumentedClient) SearchPlanetsByName(ctx context.Context, name string) (_ []*dragonball.Planet, err error) {
+	defer c.observe("search_planets", time.Now(), &err)
+	return c.next.SearchPlanetsByName(ctx, name)
+}
+
+func (c *instrumentedClient) GetPlanetByID(ctx context.Context, id int) (_ *dragonball.PlanetDetail, err error) {
+	defer c.observe("get_planet", time.Now(), &err)
+	return c.next.GetPlanetByID(ctx, id)
+}
+
+func (c *instrumentedClient) ListCharacters(ctx context.Context, page, limit int) (_ *dragonball.CharacterPage, err error) {
+	defer c.observe("list_characters", time.Now(), &err)
+	return c.next.ListCharacters(ctx, page, limit)
+}
+
+func (c *instrumentedClient) ListPlanets(ctx context.Context, page, limit int) (_ *dragonball.PlanetPage, err error) {
+	defer c.observe("list_planets", time.Now(), &err)
+	return c.next.ListPlanets(ctx, page, limit)
+}
+
+func (c *instrumentedClient) observe(operation string, start time.Time, err *error) {
+	c.metrics.upstreamDuration.
+		WithLabelValues(operation, outcome(*err)).
+		Observe(time.Since(start).Seconds())
+}
+
+// outcome classifies the errors of the client by their sentinel
+func outcome(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, dragonball.ErrCircuitOpen):
+		return "circuit_open"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, dragonball.ErrTimeout):
+		return "timeout"
+	case errors.Is(err, dragonball.ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, dragonball.ErrUnavailable):
+		return "unavailable"
+	case errors.Is(err, dragonball.ErrBadGateway):
+		return "bad_gateway"
+	default:
+		return "error"
+	}
+}
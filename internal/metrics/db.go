@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+// startKey keeps the start of the query in the statement
+const startKey = "metrics:start"
+
+// InstrumentDB observes the duration of every query made through GORM, and
+// exports the gauges of the connection pool labeled with the database name
+func (m *Metrics) InstrumentDB(db *gorm.DB, name string) error {
+	callbacks := db.Callback()
+	hooks := []struct {
+		operation string
+		before    error
+		after     error
+	}{
+		{"create",
+			callbacks.Create().Before("gorm:create").Register("metrics:before_create", startQuery),
+			callbacks.Create().After("gorm:create").Register("metrics:after_create", m.observeQuery("create"))},
+		{"query",
+			callbacks.Query().Before("gorm:query").Register("metrics:before_query", startQuery),
+			callbacks.Query().After("gorm:query").Register("metrics:after_query", m.observeQuery("query"))},
+		{"update",
+			callbacks.Update().Before("gorm:update").Register("metrics:before_update", startQuery),
+			callbacks.Update().After("gorm:update").Register("metrics:after_update", m.observeQuery("update"))},
+		{"delete",
+			callbacks.Delete().Before("gorm:delete").Register("metrics:before_delete", startQuery),
+			callbacks.Delete().After("gorm:delete").Register("metrics:after_delete", m.observeQuery("delete"))},
+		{"row",
+			callbacks.Row().Before("gorm:row").Register("metrics:before_row", startQuery),
+			callbacks.Row().After("gorm:row").Register("metrics:after_row", m.observeQuery("row"))},
+		{"raw",
+			callbacks.Raw().Before("gorm:raw").Register("metrics:before_raw", startQuery),
+			callbacks.Raw().After("gorm:raw").Register("metrics:after_raw", m.observeQuery("raw"))},
+	}
+	for _, hook := range hooks {
+		if err := errors.Join(hook.before, hook.after); err != nil {
+			return fmt.Errorf("failed to register the %s callbacks: %w", hook.operation, err)
+		}
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get the database pool: %w", err)
+	}
+	return m.registry.Register(collectors.NewDBStatsCollector(sqlDB, name))
+}
+
+func startQuery(tx *gorm.DB) {
+	tx.InstanceSet(startKey, time.Now())
+}
+
+// observeQuery observes the query started by startQuery. A lookup that
+// finds nothing is not an error.
+func (m *Metrics) observeQuery(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		value, ok := tx.InstanceGet(startKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		status := "ok"
+		if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			status = "error"
+		}
+		table := tx.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		m.dbDuration.WithLabelValues(operation, table, status).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,93 @@
+// Package metrics exposes the Prometheus metrics of the api
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes the metrics of the api
+const namespace = "dragon_ball_api"
+
+// unmatchedRoute labels the requests that match no route, so random paths
+// do not create a new series each
+const unmatchedRoute = "unmatched"
+
+// Metrics holds the collectors of the api in its own registry
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpDuration     *prometheus.HistogramVec
+	upstreamDuration *prometheus.HistogramVec
+	dbDuration       *prometheus.HistogramVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of the HTTP requests by route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		upstreamDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "upstream_request_duration_seconds",
+			Help:      "Duration of the calls to the Dragon Ball API, including the retries, by operation and outcome.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation", "outcome"}),
+		dbDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Duration of the database queries by operation, table and status.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"operation", "table", "status"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpDuration,
+		m.upstreamDuration,
+		m.dbDuration,
+	)
+	return m
+}
+
+// Registry returns the registry of the collectors, to add new ones
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler serves the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *Metrics) RegisterRoutes(r gin.IRouter) {
+	r.GET("/metrics", gin.WrapH(m.Handler())) // GET /metrics
+}
+
+// Middleware observes the duration of every request, labeled with the route
+// pattern, e.g. /characters/:name, and not with the path
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		m.httpDuration.
+			WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
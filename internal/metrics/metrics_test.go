@@ -0,0 +1,129 @@
+package metrics_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/character"
+	mock_character "github.com/gclamigueiro/dragon-ball-api/internal/character/mocks"
+	"github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
+	mock_dragonball "github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball/mocks"
+	"github.com/gclamigueiro/dragon-ball-api/internal/metrics"
+)
+
+// scrape returns the metrics in the Prometheus text format
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMiddleware(t *testing.T) {
+	m := metrics.New()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(m.Middleware())
+	m.RegisterRoutes(r)
+	r.GET("/characters/:name", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, path := range []string{"/characters/goku", "/characters/vegeta", "/random/path"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// The routes are labeled with the pattern, not the path
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := w.Body.String()
+	assert.Contains(t, body, `dragon_ball_api_http_request_duration_seconds_count{method="GET",route="/characters/:name",status="200"} 2`)
+	assert.Contains(t, body, `dragon_ball_api_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`)
+	assert.NotContains(t, body, "goku")
+}
+
+func TestInstrumentClient(t *testing.T) {
+	m := metrics.New()
+	mockClient := new(mock_dragonball.Client)
+	client := m.InstrumentClient(mockClient)
+	ctx := context.Background()
+
+	mockClient.On("GetCharacterByID", mock.Anything, 1).Return(&dragonball.CharacterDetail{}, nil)
+	mockClient.On("GetCharacterByID", mock.Anything, 2).Return(nil, dragonball.ErrCircuitOpen)
+	mockClient.On("SearchCharactersByName", mock.Anything, "Goku").Return(nil, &dragonball.APIError{StatusCode: http.StatusTooManyRequests})
+
+	_, _ = client.GetCharacterByID(ctx, 1)
+	_, _ = client.GetCharacterByID(ctx, 1)
+	_, _ = client.GetCharacterByID(ctx, 2)
+	_, _ = client.SearchCharactersByName(ctx, "Goku")
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `dragon_ball_api_upstream_request_duration_seconds_count{operation="get_character",outcome="ok"} 2`)
+	assert.Contains(t, body, `dragon_ball_api_upstream_request_duration_seconds_count{operation="get_character",outcome="circuit_open"} 1`)
+	assert.Contains(t, body, `dragon_ball_api_upstream_request_duration_seconds_count{operation="search_characters",outcome="rate_limited"} 1`)
+	mockClient.AssertExpectations(t)
+}
+
+func TestInstrumentDB(t *testing.T) {
+	m := metrics.New()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.InstrumentDB(db, "dragon_ball"))
+
+	repository := character.NewStorage(db)
+	_ = repository.Save(context.Background(), &character.Character{ID: 1, Name: "Goku"})
+	_, _ = repository.FindByIDs(context.Background(), []int{1})
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `dragon_ball_api_db_query_duration_seconds_count{operation="create",status="ok",table="characters"} 1`)
+	assert.Contains(t, body, `dragon_ball_api_db_query_duration_seconds_count{operation="query",status="ok",table="characters"} 1`)
+	assert.Contains(t, body, `go_sql_max_open_connections{db_name="dragon_ball"}`)
+}
+
+func TestRegisterCharacterService(t *testing.T) {
+	m := metrics.New()
+	serviceMetrics := &character.Metrics{}
+	require.NoError(t, m.RegisterCharacterService(serviceMetrics))
+
+	serviceMetrics.DatabaseHits.Add(3)
+	serviceMetrics.CacheMisses.Add(1)
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `dragon_ball_api_character_lookups_total{result="hit",source="database"} 3`)
+	assert.Contains(t, body, `dragon_ball_api_character_lookups_total{result="miss",source="upstream"} 1`)
+	assert.Contains(t, body, `dragon_ball_api_character_lookups_total{result="hit",source="shared_cache"} 0`)
+}
+
+func TestRegisterRepositoryCache(t *testing.T) {
+	m := metrics.New()
+	mockRepo := new(mock_character.Repository)
+	repository := character.NewCachedRepository(mockRepo, 10, time.Minute)
+	require.NoError(t, m.RegisterRepositoryCache(repository))
+
+	mockRepo.On("FindByID", mock.Anything, 1).Return(&character.Character{ID: 1, Name: "Goku"}, nil).Once()
+	_, _ = repository.FindByID(context.Background(), 1)
+	_, _ = repository.FindByID(context.Background(), 1)
+
+	body := scrape(t, m)
+	assert.Contains(t, body, "dragon_ball_api_repository_cache_hits_total 1")
+	assert.Contains(t, body, "dragon_ball_api_repository_cache_misses_total 1")
+	assert.Contains(t, body, "dragon_ball_api_repository_cache_entries 1")
+}
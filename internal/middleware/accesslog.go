@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/logging"
+)
+
+// AccessLog logs every request once it is handled, with the attributes the
+// handlers added with logging.Annotate, e.g. where a character was found.
+// Server errors are logged as errors.
+func AccessLog(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		ctx := logging.WithAnnotations(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		// The pattern, so the logs of a route can be grouped
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		level := slog.LevelInfo
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		attrs := append([]slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", c.Writer.Status()),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+		}, logging.Annotations(ctx)...)
+		logger.LogAttrs(ctx, level, "request", attrs...)
+	}
+}
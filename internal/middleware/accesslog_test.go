@@ -0,0 +1,76 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/logging"
+	"github.com/gclamigueiro/dragon-ball-api/internal/middleware"
+)
+
+// serveLogged serves the request and returns the records logged in JSON
+func serveLogged(t *testing.T, req *http.Request) []map[string]interface{} {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := logging.New(&buf, logging.FormatJSON, slog.LevelInfo)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.AccessLog(logger))
+	r.GET("/characters/:name", func(c *gin.Context) {
+		logging.Annotate(c.Request.Context(), slog.String("cache_source", "db"))
+		c.Status(http.StatusOK)
+	})
+	r.GET("/fail", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestAccessLog(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/characters/goku", nil)
+	req.Header.Set(middleware.RequestIDHeader, "abc-123")
+
+	records := serveLogged(t, req)
+	require.Len(t, records, 1)
+	record := records[0]
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "request", record["msg"])
+	assert.Equal(t, "abc-123", record["request_id"])
+	assert.Equal(t, "GET", record["method"])
+	assert.Equal(t, "/characters/:name", record["route"])
+	assert.Equal(t, "/characters/goku", record["path"])
+	assert.Equal(t, float64(http.StatusOK), record["status"])
+	assert.Contains(t, record, "latency_ms")
+	assert.Equal(t, "db", record["cache_source"])
+}
+
+func TestAccessLog_ServerErrors(t *testing.T) {
+	records := serveLogged(t, httptest.NewRequest(http.MethodGet, "/fail", nil))
+	require.Len(t, records, 1)
+	assert.Equal(t, "ERROR", records[0]["level"])
+	assert.Equal(t, float64(http.StatusInternalServerError), records[0]["status"])
+	assert.Len(t, records[0]["request_id"], 32)
+}
+
+func TestAccessLog_Unmatched(t *testing.T) {
+	records := serveLogged(t, httptest.NewRequest(http.MethodGet, "/random/path", nil))
+	require.Len(t, records, 1)
+	assert.Equal(t, "unmatched", records[0]["route"])
+	assert.Equal(t, float64(http.StatusNotFound), records[0]["status"])
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/logging"
+)
+
+// RequestIDHeader is the header used to receive and return the request ID
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is where the request ID is stored in the gin context
+const requestIDKey = "request_id"
+
+// maxRequestIDLength limits the IDs accepted from the clients
+const maxRequestIDLength = 128
+
+// RequestID propagates the X-Request-ID header of the request, or generates
+// a new ID if it is missing or invalid, and returns it in the response.
+// The ID is also kept in the context of the request, for the logs.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		c.Set(requestIDKey, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// GetRequestID returns the ID of the request, or an empty string if the
+// RequestID middleware is not installed
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// validRequestID only accepts short printable ASCII values, so the IDs of
+// the clients can be safely written to the logs
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,59 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/logging"
+	"github.com/gclamigueiro/dragon-ball-api/internal/middleware"
+)
+
+func serveWithID(id string) (string, string) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID())
+
+	var seen string
+	r.GET("/", func(c *gin.Context) {
+		seen = middleware.GetRequestID(c)
+		if logging.RequestID(c.Request.Context()) != seen {
+			seen = "the ID is not in the context of the request"
+		}
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	if id != "" {
+		req.Header.Set(middleware.RequestIDHeader, id)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return seen, w.Header().Get(middleware.RequestIDHeader)
+}
+
+func TestRequestID_Propagated(t *testing.T) {
+	seen, returned := serveWithID("abc-123")
+	assert.Equal(t, "abc-123", seen)
+	assert.Equal(t, "abc-123", returned)
+}
+
+func TestRequestID_Generated(t *testing.T) {
+	seen, returned := serveWithID("")
+	assert.Len(t, seen, 32)
+	assert.Equal(t, seen, returned)
+
+	other, _ := serveWithID("")
+	assert.NotEqual(t, seen, other)
+}
+
+func TestRequestID_InvalidIsReplaced(t *testing.T) {
+	for _, id := range []string{"has space", strings.Repeat("x", 200)} {
+		seen, _ := serveWithID(id)
+		assert.NotEqual(t, id, seen)
+		assert.Len(t, seen, 32)
+	}
+}
@@ -0,0 +1,31 @@
+package planet
+
+import (
+	"time"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/character"
+)
+
+type Planet struct {
+	ID          int        `gorm:"primaryKey;not null" json:"id"`         // Required by DB
+	Name        string     `gorm:"not null;check:name <> ''" json:"name"` // Required and non-empty string
+	IsDestroyed bool       `json:"is_destroyed"`
+	Description string     `json:"description"`
+	Image       string     `json:"image"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"` // Set when the planet was removed upstream
+
+	CharactersFetched bool `gorm:"not null;default:false" json:"-"` // Set once the characters born here are cached
+}
+
+func (p *Planet) IsValid() bool {
+	return p.ID != 0 && p.Name != ""
+}
+
+// SameData reports whether the fetched planet has the data already saved
+func (p *Planet) SameData(fetched *Planet) bool {
+	return p.Name == fetched.Name &&
+		p.IsDestroyed == fetched.IsDestroyed &&
+		p.Description == fetched.Description &&
+		p.Image == fetched.Image &&
+		character.SameTime(p.DeletedAt, fetched.DeletedAt)
+}
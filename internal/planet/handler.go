@@ -0,0 +1,98 @@
+package planet
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/problem"
+)
+
+// problems renders the errors of the planet service
+var problems = problem.NewRenderer(append([]problem.Mapping{
+	{Err: ErrPlanetNotFound, Status: http.StatusNotFound, Code: "planet_not_found", Title: "Planet not found"},
+	{Err: ErrNameEmpty, Status: http.StatusBadRequest, Code: "name_empty", Title: "Planet name cannot be empty"},
+	{Err: ErrInvalidPlanet, Status: http.StatusUnprocessableEntity, Code: "invalid_planet", Title: "Invalid planet data"},
+	{Err: ErrDatabase, Status: http.StatusInternalServerError, Code: "database_error", Title: "Database error"},
+}, problem.Upstream...)...)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) RegisterRoutes(r *gin.Engine) {
+	group := r.Group("/planets")
+	group.GET("/:name", h.GetByName) // GET /planets/:name
+	group.GET("", h.GetAll)          // GET /planets
+
+	// Gin does not allow different wildcards in the same position,
+	// so the id shares the name of the /:name wildcard
+	group.GET("/:name/characters", h.GetCharacters) // GET /planets/:id/characters
+}
+
+type getByNameRequest struct {
+	Name string `uri:"name" binding:"required"`
+}
+
+// GetByName handles GET /planets/:name
+func (h *Handler) GetByName(c *gin.Context) {
+	var req getByNameRequest
+
+	// Bind the request parameters
+	if err := c.ShouldBindUri(&req); err != nil {
+		problem.BadRequest(c, "Invalid request parameters")
+		return
+	}
+
+	// Use the service to get planet by name
+	planet, err := h.service.GetByName(c.Request.Context(), req.Name)
+	if err != nil {
+		problems.Error(c, err)
+		return
+	}
+
+	// Return the planet
+	c.JSON(http.StatusOK, planet)
+}
+
+type planetIDRequest struct {
+	ID int `uri:"name" binding:"required,min=1"`
+}
+
+// GetCharacters handles GET /planets/:id/characters
+func (h *Handler) GetCharacters(c *gin.Context) {
+	var req planetIDRequest
+
+	// Bind the request parameters
+	if err := c.ShouldBindUri(&req); err != nil {
+		problem.BadRequest(c, "Invalid request parameters")
+		return
+	}
+
+	// Use the service to get the characters born in the planet
+	characters, err := h.service.GetCharacters(c.Request.Context(), req.ID)
+	if err != nil {
+		problems.Error(c, err)
+		return
+	}
+
+	// Return the characters
+	c.JSON(http.StatusOK, characters)
+}
+
+// List all planets saved in the database
+func (h *Handler) GetAll(c *gin.Context) {
+	// Use the service to get all planets
+	planets, err := h.service.GetAll(c.Request.Context())
+	if err != nil {
+		problems.Error(c, err)
+		return
+	}
+
+	// Return the list of planets
+	c.JSON(http.StatusOK, planets)
+}
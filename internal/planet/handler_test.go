@@ -0,0 +1,162 @@
+package planet_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/character"
+	mock_character "github.com/gclamigueiro/dragon-ball-api/internal/character/mocks"
+	"github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
+	mock_dragonball "github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball/mocks"
+	"github.com/gclamigueiro/dragon-ball-api/internal/planet"
+	"github.com/gclamigueiro/dragon-ball-api/internal/planet/mocks"
+)
+
+func setupRouter(handler *planet.Handler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+	return r
+}
+
+func TestGetByName_Success(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := planet.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	mockService.On("GetByName", mock.Anything, "Namek").Return(&planet.Planet{ID: 1, Name: "Namek"}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/planets/Namek", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp planet.Planet
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, "Namek", resp.Name)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetByName_NotFound(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := planet.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	mockService.On("GetByName", mock.Anything, "Pluto").Return(nil, planet.ErrPlanetNotFound)
+
+	req, _ := http.NewRequest(http.MethodGet, "/planets/Pluto", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetCharacters_Success(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := planet.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	expected := []*character.Character{{ID: 3, Name: "Piccolo"}}
+	mockService.On("GetCharacters", mock.Anything, 1).Return(expected, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/planets/1/characters", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp []character.Character
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Len(t, resp, 1)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetCharacters_InvalidID(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := planet.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	req, _ := http.NewRequest(http.MethodGet, "/planets/namek/characters", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAll_Success(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := planet.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	mockService.On("GetAll", mock.Anything).Return([]*planet.Planet{{ID: 1, Name: "Namek"}, {ID: 2, Name: "Tierra"}}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/planets", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp []planet.Planet
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Len(t, resp, 2)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAll_InternalError(t *testing.T) {
+	mockService := new(mocks.Service)
+	handler := planet.NewHandler(mockService)
+	router := setupRouter(handler)
+
+	mockService.On("GetAll", mock.Anything).Return(nil, errors.New("db error"))
+
+	req, _ := http.NewRequest(http.MethodGet, "/planets", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var resp map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, "internal_error", resp["code"])
+	assert.NotContains(t, w.Body.String(), "db error")
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAll_DatabaseError(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	handler := planet.NewHandler(planet.NewService(mockClient, mockRepo, new(mock_character.Repository)))
+	router := setupRouter(handler)
+
+	mockClient.On("ListPlanets", mock.Anything, 1, 50).Return(&dragonball.PlanetPage{}, nil)
+	mockRepo.On("SaveAll", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("FindAll", mock.Anything).Return(nil, errors.New("pq: connection refused"))
+
+	req, _ := http.NewRequest(http.MethodGet, "/planets", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var resp map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, "database_error", resp["code"])
+	assert.Equal(t, "/problems/database_error", resp["type"])
+	assert.NotContains(t, w.Body.String(), "connection refused")
+	mockRepo.AssertExpectations(t)
+}
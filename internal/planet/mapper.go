@@ -0,0 +1,28 @@
+package planet
+
+import (
+	"github.com/gclamigueiro/dragon-ball-api/internal/character"
+	"github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
+)
+
+func FromAPIResponse(apiPlanet *dragonball.Planet) *Planet {
+	return &Planet{
+		ID:          apiPlanet.ID,
+		Name:        apiPlanet.Name,
+		IsDestroyed: apiPlanet.IsDestroyed,
+		Description: apiPlanet.Description,
+		Image:       apiPlanet.Image,
+		DeletedAt:   apiPlanet.DeletedAt,
+	}
+}
+
+// CharactersFromAPIResponse maps the characters born in the planet, linking them to it
+func CharactersFromAPIResponse(planetID int, apiCharacters []*dragonball.Character) []*character.Character {
+	characters := make([]*character.Character, 0, len(apiCharacters))
+	for _, apiCharacter := range apiCharacters {
+		char := character.FromAPIResponse(apiCharacter)
+		char.OriginPlanetID = &planetID
+		characters = append(characters, char)
+	}
+	return characters
+}
@@ -0,0 +1,188 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	character "github.com/gclamigueiro/dragon-ball-api/internal/character"
+
+	mock "github.com/stretchr/testify/mock"
+
+	planet "github.com/gclamigueiro/dragon-ball-api/internal/planet"
+)
+
+// Repository is an autogenerated mock type for the Repository type
+type Repository struct {
+	mock.Mock
+}
+
+// FindAll provides a mock function with given fields: ctx
+func (_m *Repository) FindAll(ctx context.Context) ([]*planet.Planet, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindAll")
+	}
+
+	var r0 []*planet.Planet
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*planet.Planet, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*planet.Planet); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*planet.Planet)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByID provides a mock function with given fields: ctx, id
+func (_m *Repository) FindByID(ctx context.Context, id int) (*planet.Planet, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByID")
+	}
+
+	var r0 *planet.Planet
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*planet.Planet, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *planet.Planet); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*planet.Planet)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByName provides a mock function with given fields: ctx, name
+func (_m *Repository) FindByName(ctx context.Context, name string) (*planet.Planet, error) {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByName")
+	}
+
+	var r0 *planet.Planet
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*planet.Planet, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *planet.Planet); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*planet.Planet)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindCharacters provides a mock function with given fields: ctx, planetID
+func (_m *Repository) FindCharacters(ctx context.Context, planetID int) ([]*character.Character, error) {
+	ret := _m.Called(ctx, planetID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindCharacters")
+	}
+
+	var r0 []*character.Character
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*character.Character, error)); ok {
+		return rf(ctx, planetID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*character.Character); ok {
+		r0 = rf(ctx, planetID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*character.Character)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, planetID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveAll provides a mock function with given fields: ctx, planets
+func (_m *Repository) SaveAll(ctx context.Context, planets []*planet.Planet) error {
+	ret := _m.Called(ctx, planets)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveAll")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*planet.Planet) error); ok {
+		r0 = rf(ctx, planets)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveCharactersFetched provides a mock function with given fields: ctx, _a1
+func (_m *Repository) SaveCharactersFetched(ctx context.Context, _a1 *planet.Planet) error {
+	ret := _m.Called(ctx, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveCharactersFetched")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *planet.Planet) error); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewRepository creates a new instance of Repository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repository {
+	mock := &Repository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,140 @@
+package planet
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/character"
+	"github.com/gclamigueiro/dragon-ball-api/internal/db"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	FindAll(ctx context.Context) ([]*Planet, error)
+	FindByID(ctx context.Context, id int) (*Planet, error)
+	FindByName(ctx context.Context, name string) (*Planet, error)
+	SaveAll(ctx context.Context, planets []*Planet) error
+	FindCharacters(ctx context.Context, planetID int) ([]*character.Character, error)
+	// SaveCharactersFetched stores the planet if it is new and marks its
+	// characters as fetched. The characters are saved by their own repository.
+	SaveCharactersFetched(ctx context.Context, planet *Planet) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// StorageOption configures the optional behaviour of the storage
+type StorageOption func(*repository)
+
+// WithStorageLogger logs the queries of the storage to the logger instead of
+// the one of the connection
+func WithStorageLogger(logger *slog.Logger) StorageOption {
+	return func(r *repository) {
+		r.db = db.WithLogger(r.db, logger)
+	}
+}
+
+func NewStorage(conn *gorm.DB, opts ...StorageOption) Repository {
+	r := &repository{db: conn}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func (r *repository) FindAll(ctx context.Context) ([]*Planet, error) {
+	var planets []*Planet
+
+	// Retrieve all planets from the database, there are only a few of them
+	err := r.db.WithContext(ctx).Order("id").Find(&planets).Error
+	if err != nil {
+		return nil, err
+	}
+	return planets, nil
+}
+
+func (r *repository) FindByID(ctx context.Context, id int) (*Planet, error) {
+	var planet Planet
+
+	err := r.db.WithContext(ctx).Take(&planet, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &planet, nil
+}
+
+// FindByName only returns exact matches (case-insensitive), partial
+// matches are resolved by the service with the api
+func (r *repository) FindByName(ctx context.Context, name string) (*Planet, error) {
+	var planet Planet
+
+	err := r.db.WithContext(ctx).
+		Where("LOWER(name) = LOWER(?)", name).
+		Take(&planet).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &planet, nil
+}
+
+func (r *repository) SaveAll(ctx context.Context, planets []*Planet) error {
+	if len(planets) == 0 {
+		return nil
+	}
+	// If the planet already exists, refresh its data but keep characters_fetched
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns(planetDataColumns),
+	}).Create(planets).Error
+
+	return err
+}
+
+// planetDataColumns are the columns that come from the api
+var planetDataColumns = []string{"name", "is_destroyed", "description", "image", "deleted_at"}
+
+func (r *repository) FindCharacters(ctx context.Context, planetID int) ([]*character.Character, error) {
+	var characters []*character.Character
+
+	err := r.db.WithContext(ctx).
+		Where("origin_planet_id = ?", planetID).
+		Order("id").
+		Find(&characters).Error
+	if err != nil {
+		return nil, err
+	}
+	return characters, nil
+}
+
+// SaveCharactersFetched marks the characters of the planet as fetched, so
+// the api is not asked again for this planet
+func (r *repository) SaveCharactersFetched(ctx context.Context, planet *Planet) error {
+	if planet == nil {
+		return errors.New("planet cannot be nil")
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The planet may not be cached yet
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoNothing: true,
+		}).Create(planet).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&Planet{}).
+			Where("id = ?", planet.ID).
+			Update("characters_fetched", true).Error
+	})
+}
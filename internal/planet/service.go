@@ -0,0 +1,292 @@
+package planet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/cache"
+	"github.com/gclamigueiro/dragon-ball-api/internal/character"
+	"github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
+)
+
+var (
+	ErrPlanetNotFound = errors.New("planet not found")
+	ErrNameEmpty      = errors.New("planet name cannot be empty")
+	ErrInvalidPlanet  = errors.New("invalid planet data")
+	ErrDatabase       = errors.New("database error")
+)
+
+type Service interface {
+	GetAll(ctx context.Context) ([]*Planet, error)
+	GetByName(ctx context.Context, name string) (*Planet, error)
+	GetCharacters(ctx context.Context, id int) ([]*character.Character, error)
+}
+
+// listPageSize is how many planets are asked to the api per page of the listing
+const listPageSize = 50
+
+type service struct {
+	dgzClient  dragonball.Client
+	repository Repository
+	characters character.Repository
+	cacheTTL   time.Duration
+	cache      cache.Cache // Shared cache of the characters, nil if disabled
+	logger     *slog.Logger
+
+	// listings deduplicates the concurrent fetches of the listing
+	listings singleflight.Group
+	mu       sync.Mutex
+	listedAt time.Time // Zero until the whole listing of the api is saved
+}
+
+// Option configures the optional behaviour of the service
+type Option func(*service)
+
+// WithCacheTTL sets how long the saved listing is served before it is
+// fetched again from the api. Zero, the default, never fetches it again.
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(s *service) {
+		s.cacheTTL = ttl
+	}
+}
+
+// WithCache sets the cache the character service shares with the replicas,
+// the characters saved with a planet are removed from it so the stale
+// copies are not served
+func WithCache(c cache.Cache) Option {
+	return func(s *service) {
+		s.cache = c
+	}
+}
+
+// WithLogger sets the logger of the service, slog.Default() if not set
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *service) {
+		s.logger = logger
+	}
+}
+
+// NewService saves the characters born in a planet through the characters
+// repository, so its cache sees them
+func NewService(dgzClient dragonball.Client, repository Repository, characters character.Repository, opts ...Option) Service {
+	s := &service{
+		dgzClient:  dgzClient,
+		repository: repository,
+		characters: characters,
+		logger:     slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// GetAll retrieves all planets. The first call saves the whole listing of the
+// api, so the planets not looked up yet are included, and later calls are
+// served from the local database until the listing is stale.
+func (s *service) GetAll(ctx context.Context) ([]*Planet, error) {
+	listErr := s.saveListing(ctx)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	planets, err := s.repository.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get all planets: %w", ErrDatabase, err)
+	}
+	// The planets already saved are better than nothing if the api is not available
+	if listErr != nil && len(planets) == 0 {
+		return nil, listErr
+	}
+	if planets == nil {
+		planets = []*Planet{}
+	}
+	return planets, nil
+}
+
+// saveListing saves every planet of the api if the listing was never saved
+// or is stale. Concurrent calls share a single fetch, and a failed attempt is
+// retried on the next call.
+func (s *service) saveListing(ctx context.Context) error {
+	if s.listingFresh() {
+		return nil
+	}
+
+	result := s.listings.DoChan("planets", func() (interface{}, error) {
+		// The fetch must not fail for everyone if the caller that started it goes away
+		ctx := context.WithoutCancel(ctx)
+
+		var planets []*Planet
+		for page := 1; ; page++ {
+			resp, err := s.dgzClient.ListPlanets(ctx, page, listPageSize)
+			if err != nil {
+				return nil, dragonball.WrapError(err)
+			}
+			for _, item := range resp.Items {
+				if planet := FromAPIResponse(item); planet.IsValid() {
+					planets = append(planets, planet)
+				}
+			}
+			if !resp.Meta.HasNext() {
+				break
+			}
+		}
+
+		if err := s.repository.SaveAll(ctx, planets); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		s.mu.Lock()
+		s.listedAt = time.Now()
+		s.mu.Unlock()
+		return nil, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case res := <-result:
+		return res.Err
+	}
+}
+
+// listingFresh reports whether the saved listing can be served
+func (s *service) listingFresh() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listedAt.IsZero() {
+		return false
+	}
+	return s.cacheTTL <= 0 || time.Since(s.listedAt) < s.cacheTTL
+}
+
+// GetByName retrieves a planet by name (case-insensitive).
+// An exact match always wins, otherwise the first planet starting with the name.
+func (s *service) GetByName(ctx context.Context, name string) (*Planet, error) {
+
+	if name == "" {
+		return nil, ErrNameEmpty
+	}
+
+	// Try to find the planet in the local database
+	planet, err := s.repository.FindByName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	if planet != nil {
+		return planet, nil
+	}
+
+	// Fetch every match from external API
+	apiPlanets, err := s.dgzClient.SearchPlanetsByName(ctx, name)
+	if err != nil {
+		return nil, dragonball.WrapError(err)
+	}
+
+	planets := make([]*Planet, 0, len(apiPlanets))
+	for _, apiPlanet := range apiPlanets {
+		if planet := FromAPIResponse(apiPlanet); planet.IsValid() {
+			planets = append(planets, planet)
+		}
+	}
+
+	planet = bestMatch(name, planets)
+	if planet == nil {
+		return nil, ErrPlanetNotFound
+	}
+
+	// Cache every match, so later exact lookups are answered locally
+	if err := s.repository.SaveAll(ctx, planets); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return planet, nil
+}
+
+// GetCharacters retrieves the characters born in the planet with the id.
+// They are fetched from the api the first time and then served from the local database.
+func (s *service) GetCharacters(ctx context.Context, id int) ([]*character.Character, error) {
+	planet, err := s.repository.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	if planet != nil && planet.CharactersFetched {
+		characters, err := s.repository.FindCharacters(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+		return characters, nil
+	}
+
+	// Fetch the planet detail from external API
+	detail, err := s.dgzClient.GetPlanetByID(ctx, id)
+	if err != nil {
+		return nil, dragonball.WrapError(err)
+	}
+	if detail == nil {
+		return nil, ErrPlanetNotFound
+	}
+
+	planet = FromAPIResponse(&detail.Planet)
+	if !planet.IsValid() {
+		return nil, fmt.Errorf("%w: planet data is invalid received from the api", ErrInvalidPlanet)
+	}
+
+	characters := make([]*character.Character, 0, len(detail.Characters))
+	for _, char := range CharactersFromAPIResponse(planet.ID, detail.Characters) {
+		if char.IsValid() {
+			characters = append(characters, char)
+		}
+	}
+
+	// The planet is marked last, so a failure fetches the characters again
+	if err := s.characters.SaveAll(ctx, characters); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+	s.invalidate(ctx, characters)
+	if err := s.repository.SaveCharactersFetched(ctx, planet); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return characters, nil
+}
+
+// invalidate removes the saved characters from the shared cache. It is best
+// effort, the errors are only logged and the copies expire on their own.
+func (s *service) invalidate(ctx context.Context, characters []*character.Character) {
+	if s.cache == nil || len(characters) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(characters))
+	for _, char := range characters {
+		keys = append(keys, character.NameCacheKey(char.Name))
+	}
+	if err := s.cache.Delete(ctx, keys...); err != nil {
+		s.logger.WarnContext(ctx, "failed to invalidate the cache", "keys", keys, "error", err)
+	}
+}
+
+// bestMatch returns the exact match for the name, or the first planet
+// starting with it, or nil if none matches
+func bestMatch(name string, planets []*Planet) *Planet {
+	var prefixMatch *Planet
+	for _, planet := range planets {
+		if strings.EqualFold(planet.Name, name) {
+			return planet
+		}
+		if prefixMatch == nil && strings.HasPrefix(strings.ToLower(planet.Name), strings.ToLower(name)) {
+			prefixMatch = planet
+		}
+	}
+	return prefixMatch
+}
@@ -0,0 +1,298 @@
+package planet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/cache"
+	"github.com/gclamigueiro/dragon-ball-api/internal/character"
+	mock_character "github.com/gclamigueiro/dragon-ball-api/internal/character/mocks"
+	"github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
+	mock_dragonball "github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball/mocks"
+	"github.com/gclamigueiro/dragon-ball-api/internal/planet"
+	"github.com/gclamigueiro/dragon-ball-api/internal/planet/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestService_GetByName_FoundInRepository(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := planet.NewService(mockClient, mockRepo, new(mock_character.Repository))
+	ctx := context.Background()
+
+	expected := &planet.Planet{ID: 1, Name: "Namek"}
+	mockRepo.On("FindByName", mock.Anything, "namek").Return(expected, nil)
+
+	result, err := svc.GetByName(ctx, "namek")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetByName_FoundInAPI(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := planet.NewService(mockClient, mockRepo, new(mock_character.Repository))
+	ctx := context.Background()
+
+	mockRepo.On("FindByName", mock.Anything, "Tierra").Return(nil, nil)
+	apiPlanets := []*dragonball.Planet{
+		{ID: 9, Name: "Tierra del Futuro"},
+		{ID: 2, Name: "Tierra"},
+	}
+	mockClient.On("SearchPlanetsByName", mock.Anything, "Tierra").Return(apiPlanets, nil)
+	mockRepo.On("SaveAll", mock.Anything, mock.MatchedBy(func(planets []*planet.Planet) bool {
+		return len(planets) == 2
+	})).Return(nil)
+
+	result, err := svc.GetByName(ctx, "Tierra")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.ID)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetByName_NotFound(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := planet.NewService(mockClient, mockRepo, new(mock_character.Repository))
+	ctx := context.Background()
+
+	mockRepo.On("FindByName", mock.Anything, "Pluto").Return(nil, nil)
+	mockClient.On("SearchPlanetsByName", mock.Anything, "Pluto").Return([]*dragonball.Planet{}, nil)
+
+	result, err := svc.GetByName(ctx, "Pluto")
+	assert.ErrorIs(t, err, planet.ErrPlanetNotFound)
+	assert.Nil(t, result)
+}
+
+func TestService_GetByName_EmptyName(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := planet.NewService(mockClient, mockRepo, new(mock_character.Repository))
+	ctx := context.Background()
+
+	result, err := svc.GetByName(ctx, "")
+	assert.ErrorIs(t, err, planet.ErrNameEmpty)
+	assert.Nil(t, result)
+}
+
+func TestService_GetCharacters_Cached(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := planet.NewService(mockClient, mockRepo, new(mock_character.Repository))
+	ctx := context.Background()
+
+	expected := []*character.Character{{ID: 3, Name: "Piccolo"}}
+	mockRepo.On("FindByID", mock.Anything, 1).Return(&planet.Planet{ID: 1, Name: "Namek", CharactersFetched: true}, nil)
+	mockRepo.On("FindCharacters", mock.Anything, 1).Return(expected, nil)
+
+	result, err := svc.GetCharacters(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetCharacters_FetchedFromAPI(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	mockCharacters := new(mock_character.Repository)
+	shared := cache.NewMemory(10)
+	svc := planet.NewService(mockClient, mockRepo, mockCharacters, planet.WithCache(shared))
+	ctx := context.Background()
+
+	// Piccolo is cached with the data saved before
+	assert.NoError(t, shared.Set(ctx, character.NameCacheKey("Piccolo"), []byte("{}"), time.Hour))
+
+	mockRepo.On("FindByID", mock.Anything, 1).Return(nil, nil)
+	detail := &dragonball.PlanetDetail{
+		Planet:     dragonball.Planet{ID: 1, Name: "Namek", IsDestroyed: true},
+		Characters: []*dragonball.Character{{ID: 3, Name: "Piccolo"}, {ID: 24, Name: "Dende"}},
+	}
+	mockClient.On("GetPlanetByID", mock.Anything, 1).Return(detail, nil)
+	mockCharacters.On("SaveAll", mock.Anything,
+		mock.MatchedBy(func(chars []*character.Character) bool { return len(chars) == 2 }),
+	).Return(nil)
+	mockRepo.On("SaveCharactersFetched", mock.Anything,
+		mock.MatchedBy(func(p *planet.Planet) bool { return p.ID == 1 && p.IsDestroyed }),
+	).Return(nil)
+
+	result, err := svc.GetCharacters(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, 1, *result[0].OriginPlanetID)
+
+	_, ok, _ := shared.Get(ctx, character.NameCacheKey("Piccolo"))
+	assert.False(t, ok)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+	mockCharacters.AssertExpectations(t)
+}
+
+func TestService_GetCharacters_SaveCharactersFails(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	mockCharacters := new(mock_character.Repository)
+	svc := planet.NewService(mockClient, mockRepo, mockCharacters)
+	ctx := context.Background()
+
+	mockRepo.On("FindByID", mock.Anything, 1).Return(nil, nil)
+	mockClient.On("GetPlanetByID", mock.Anything, 1).Return(&dragonball.PlanetDetail{
+		Planet:     dragonball.Planet{ID: 1, Name: "Namek"},
+		Characters: []*dragonball.Character{{ID: 3, Name: "Piccolo"}},
+	}, nil)
+	mockCharacters.On("SaveAll", mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+
+	// The planet is not marked, so the characters are fetched again next time
+	_, err := svc.GetCharacters(ctx, 1)
+	assert.ErrorIs(t, err, planet.ErrDatabase)
+	mockRepo.AssertNotCalled(t, "SaveCharactersFetched", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+	mockCharacters.AssertExpectations(t)
+}
+
+func TestService_GetCharacters_NotFound(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := planet.NewService(mockClient, mockRepo, new(mock_character.Repository))
+	ctx := context.Background()
+
+	mockRepo.On("FindByID", mock.Anything, 99).Return(nil, nil)
+	mockClient.On("GetPlanetByID", mock.Anything, 99).Return(nil, nil)
+
+	result, err := svc.GetCharacters(ctx, 99)
+	assert.ErrorIs(t, err, planet.ErrPlanetNotFound)
+	assert.Nil(t, result)
+}
+
+func TestService_GetAll_Error(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := planet.NewService(mockClient, mockRepo, new(mock_character.Repository))
+	ctx := context.Background()
+
+	mockClient.On("ListPlanets", mock.Anything, 1, 50).Return(&dragonball.PlanetPage{}, nil)
+	mockRepo.On("SaveAll", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("FindAll", mock.Anything).Return(nil, errors.New("db error"))
+
+	result, err := svc.GetAll(ctx)
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_GetAll_SavesTheListingOnce(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := planet.NewService(mockClient, mockRepo, new(mock_character.Repository))
+	ctx := context.Background()
+
+	mockClient.On("ListPlanets", mock.Anything, 1, 50).Return(&dragonball.PlanetPage{
+		Items: []*dragonball.Planet{{ID: 1, Name: "Namek"}, {ID: 0, Name: "Invalid"}},
+		Meta:  dragonball.PageMeta{CurrentPage: 1, TotalPages: 2},
+	}, nil).Once()
+	mockClient.On("ListPlanets", mock.Anything, 2, 50).Return(&dragonball.PlanetPage{
+		Items: []*dragonball.Planet{{ID: 2, Name: "Tierra"}},
+		Meta:  dragonball.PageMeta{CurrentPage: 2, TotalPages: 2},
+	}, nil).Once()
+	mockRepo.On("SaveAll", mock.Anything, mock.MatchedBy(func(planets []*planet.Planet) bool {
+		return len(planets) == 2 && planets[0].ID == 1 && planets[1].ID == 2
+	})).Return(nil).Once()
+	saved := []*planet.Planet{{ID: 1, Name: "Namek"}, {ID: 2, Name: "Tierra"}}
+	mockRepo.On("FindAll", mock.Anything).Return(saved, nil).Twice()
+
+	result, err := svc.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, saved, result)
+
+	// Served from the database from now on
+	result, err = svc.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, saved, result)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetAll_ServesSavedPlanetsWhenAPIFails(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := planet.NewService(mockClient, mockRepo, new(mock_character.Repository))
+	ctx := context.Background()
+
+	mockClient.On("ListPlanets", mock.Anything, 1, 50).Return(nil, dragonball.ErrUnavailable)
+	saved := []*planet.Planet{{ID: 1, Name: "Namek"}}
+	mockRepo.On("FindAll", mock.Anything).Return(saved, nil).Once()
+
+	result, err := svc.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, saved, result)
+
+	// Without saved planets the error is returned, and the listing retried
+	mockRepo.On("FindAll", mock.Anything).Return([]*planet.Planet{}, nil).Once()
+	result, err = svc.GetAll(ctx)
+	assert.ErrorIs(t, err, dragonball.ErrUnavailable)
+	assert.Nil(t, result)
+	mockClient.AssertNumberOfCalls(t, "ListPlanets", 2)
+}
+
+func TestService_GetAll_FetchesStaleListingAgain(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := planet.NewService(mockClient, mockRepo, new(mock_character.Repository), planet.WithCacheTTL(10*time.Millisecond))
+	ctx := context.Background()
+
+	mockClient.On("ListPlanets", mock.Anything, 1, 50).Return(&dragonball.PlanetPage{
+		Items: []*dragonball.Planet{{ID: 1, Name: "Namek"}},
+		Meta:  dragonball.PageMeta{CurrentPage: 1, TotalPages: 1},
+	}, nil).Twice()
+	mockRepo.On("SaveAll", mock.Anything, mock.Anything).Return(nil).Twice()
+	mockRepo.On("FindAll", mock.Anything).Return([]*planet.Planet{{ID: 1, Name: "Namek"}}, nil)
+
+	_, err := svc.GetAll(ctx)
+	assert.NoError(t, err)
+	_, err = svc.GetAll(ctx)
+	assert.NoError(t, err)
+	mockClient.AssertNumberOfCalls(t, "ListPlanets", 1)
+
+	// Planets added upstream later are listed once it is stale
+	time.Sleep(20 * time.Millisecond)
+	_, err = svc.GetAll(ctx)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestService_GetAll_CallerCanceledWhileListing(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockClient := new(mock_dragonball.Client)
+	svc := planet.NewService(mockClient, mockRepo, new(mock_character.Repository))
+
+	release := make(chan struct{})
+	mockClient.On("ListPlanets", mock.Anything, 1, 50).
+		Run(func(mock.Arguments) { <-release }).
+		Return(&dragonball.PlanetPage{Meta: dragonball.PageMeta{CurrentPage: 1, TotalPages: 1}}, nil).Once()
+	mockRepo.On("SaveAll", mock.Anything, mock.Anything).Return(nil).Once()
+
+	// Both callers wait for the same fetch, the canceled one gives up
+	first := make(chan error, 1)
+	go func() {
+		_, err := svc.GetAll(context.Background())
+		first <- err
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := svc.GetAll(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	mockRepo.On("FindAll", mock.Anything).Return([]*planet.Planet{}, nil).Once()
+	close(release)
+	assert.NoError(t, <-first)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,133 @@
+// Package problem renders errors as RFC 7807 problem details
+package problem
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/middleware"
+)
+
+// ContentType is the media type of the problem responses
+const ContentType = "application/problem+json"
+
+// Stable codes shared by every domain
+const (
+	CodeInvalidRequest = "invalid_request"
+	CodeInternal       = "internal_error"
+	CodeCanceled       = "request_canceled"
+	CodeTimeout        = "request_timeout"
+)
+
+// StatusClientClosedRequest is the non-standard status of the requests the
+// client canceled before the response, as nginx logs them
+const StatusClientClosedRequest = 499
+
+// Problem is the body of an error response (RFC 7807).
+// Code is a stable machine-readable identifier of the error.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// New builds a problem whose type is derived from the code
+func New(status int, code, title, detail string) Problem {
+	return Problem{
+		Type:   TypeURI(code),
+		Title:  title,
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// TypeURI returns the URI reference identifying the problem type of a code
+func TypeURI(code string) string {
+	return "/problems/" + code
+}
+
+// Write sends the problem, filling the instance and request ID of the current request
+func Write(c *gin.Context, p Problem) {
+	if p.Instance == "" {
+		p.Instance = c.Request.URL.RequestURI()
+	}
+	if p.RequestID == "" {
+		p.RequestID = middleware.GetRequestID(c)
+	}
+
+	c.Render(p.Status, render{problem: p})
+	c.Abort()
+}
+
+// BadRequest sends a 400 problem for invalid parameters
+func BadRequest(c *gin.Context, detail string) {
+	Write(c, New(http.StatusBadRequest, CodeInvalidRequest, "Invalid request", detail))
+}
+
+// Mapping turns the errors matching Err into a problem
+type Mapping struct {
+	Err    error
+	Status int
+	Code   string
+	Title  string
+	// Expose uses the message of the error as the detail. Only for errors
+	// whose message is safe to show, like validation errors.
+	Expose bool
+}
+
+// Renderer converts the errors of a service into problems.
+// Errors without a mapping are rendered as a 500 without any detail.
+type Renderer struct {
+	mappings []Mapping
+}
+
+// NewRenderer checks the mappings in order, the first match wins
+func NewRenderer(mappings ...Mapping) *Renderer {
+	return &Renderer{mappings: mappings}
+}
+
+// Problem returns the problem for the error. A canceled request is not an
+// error of the service, whatever failed because of it.
+func (r *Renderer) Problem(err error) Problem {
+	if errors.Is(err, context.Canceled) {
+		return New(StatusClientClosedRequest, CodeCanceled, "Request canceled", "")
+	}
+	for _, m := range r.mappings {
+		if !errors.Is(err, m.Err) {
+			continue
+		}
+		p := New(m.Status, m.Code, m.Title, m.Err.Error())
+		if m.Expose {
+			p.Detail = err.Error()
+		}
+		return p
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return New(http.StatusGatewayTimeout, CodeTimeout, "Request timed out", "")
+	}
+	return New(http.StatusInternalServerError, CodeInternal, "Internal server error", "")
+}
+
+// Error writes the problem for the error. Server errors are logged with
+// their full message, which is never sent to the client.
+func (r *Renderer) Error(c *gin.Context, err error) {
+	p := r.Problem(err)
+	switch {
+	case p.Status == StatusClientClosedRequest:
+		slog.DebugContext(c.Request.Context(), "request canceled by the client",
+			"method", c.Request.Method, "path", c.Request.URL.Path, "error", err)
+	case p.Status >= http.StatusInternalServerError:
+		slog.ErrorContext(c.Request.Context(), "request failed",
+			"method", c.Request.Method, "path", c.Request.URL.Path, "error", err)
+	}
+	Write(c, p)
+}
@@ -0,0 +1,159 @@
+package problem_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
+	"github.com/gclamigueiro/dragon-ball-api/internal/middleware"
+	"github.com/gclamigueiro/dragon-ball-api/internal/problem"
+)
+
+var (
+	errNotFound = errors.New("thing not found")
+	errInvalid  = errors.New("invalid thing")
+)
+
+var renderer = problem.NewRenderer(append([]problem.Mapping{
+	{Err: errNotFound, Status: http.StatusNotFound, Code: "thing_not_found", Title: "Thing not found"},
+	{Err: errInvalid, Status: http.StatusBadRequest, Code: "invalid_thing", Title: "Invalid thing", Expose: true},
+}, problem.Upstream...)...)
+
+func serve(err error) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID())
+	r.GET("/things/:name", func(c *gin.Context) {
+		renderer.Error(c, err)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/things/goku?x=1", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-123")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRenderer_MappedError(t *testing.T) {
+	w := serve(fmt.Errorf("lookup: %w", errNotFound))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+
+	var p problem.Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+	assert.Equal(t, problem.Problem{
+		Type:      "/problems/thing_not_found",
+		Title:     "Thing not found",
+		Status:    http.StatusNotFound,
+		Detail:    "thing not found",
+		Instance:  "/things/goku?x=1",
+		Code:      "thing_not_found",
+		RequestID: "req-123",
+	}, p)
+}
+
+func TestRenderer_ExposedDetail(t *testing.T) {
+	w := serve(fmt.Errorf("%w: name is too long", errInvalid))
+
+	var p problem.Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+	assert.Equal(t, http.StatusBadRequest, p.Status)
+	assert.Equal(t, "invalid thing: name is too long", p.Detail)
+}
+
+func TestRenderer_UnknownErrorHidesMessage(t *testing.T) {
+	w := serve(errors.New(`pq: password authentication failed for user "postgres"`))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotContains(t, w.Body.String(), "postgres")
+
+	var p problem.Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+	assert.Equal(t, problem.CodeInternal, p.Code)
+}
+
+func TestRenderer_UpstreamErrors(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{&dragonball.APIError{StatusCode: http.StatusTooManyRequests}, http.StatusTooManyRequests, "upstream_rate_limited"},
+		{&dragonball.APIError{StatusCode: http.StatusInternalServerError}, http.StatusBadGateway, "upstream_bad_gateway"},
+		{&dragonball.APIError{StatusCode: http.StatusServiceUnavailable}, http.StatusServiceUnavailable, "upstream_unavailable"},
+		{dragonball.ErrCircuitOpen, http.StatusServiceUnavailable, "upstream_unavailable"},
+		{&dragonball.APIError{StatusCode: http.StatusGatewayTimeout}, http.StatusGatewayTimeout, "upstream_timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			p := renderer.Problem(fmt.Errorf("external API error: %w", tt.err))
+			assert.Equal(t, tt.status, p.Status)
+			assert.Equal(t, tt.code, p.Code)
+		})
+	}
+}
+
+func TestRenderer_ContextErrors(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		// Canceled wins over the mapping of what failed because of it
+		{fmt.Errorf("lookup: %w: %w", errNotFound, context.Canceled), problem.StatusClientClosedRequest, problem.CodeCanceled},
+		{dragonball.WrapError(context.Canceled), problem.StatusClientClosedRequest, problem.CodeCanceled},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout, problem.CodeTimeout},
+		{fmt.Errorf("%w: %w", dragonball.ErrTimeout, context.DeadlineExceeded), http.StatusGatewayTimeout, "upstream_timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			p := renderer.Problem(tt.err)
+			assert.Equal(t, tt.status, p.Status)
+			assert.Equal(t, tt.code, p.Code)
+		})
+	}
+}
+
+func TestRenderer_CanceledIsNotLoggedAsError(t *testing.T) {
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	w := serve(dragonball.WrapError(context.Canceled))
+
+	assert.Equal(t, problem.StatusClientClosedRequest, w.Code)
+	assert.Empty(t, logs.String())
+}
+
+func TestBadRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/things", func(c *gin.Context) {
+		problem.BadRequest(c, "Invalid query parameters")
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/things", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var p problem.Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+	assert.Equal(t, http.StatusBadRequest, p.Status)
+	assert.Equal(t, problem.CodeInvalidRequest, p.Code)
+	assert.Equal(t, "Invalid query parameters", p.Detail)
+	assert.Empty(t, p.RequestID)
+}
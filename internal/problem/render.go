@@ -0,0 +1,20 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// render writes a problem with the problem+json content type
+type render struct {
+	problem Problem
+}
+
+func (r render) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return json.NewEncoder(w).Encode(r.problem)
+}
+
+func (r render) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", ContentType)
+}
@@ -0,0 +1,17 @@
+package problem
+
+import (
+	"net/http"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
+)
+
+// Upstream maps the errors of the external API. The details are never
+// exposed because they include internal URLs.
+var Upstream = []Mapping{
+	{Err: dragonball.ErrRateLimited, Status: http.StatusTooManyRequests, Code: "upstream_rate_limited", Title: "External API rate limit exceeded"},
+	{Err: dragonball.ErrUnavailable, Status: http.StatusServiceUnavailable, Code: "upstream_unavailable", Title: "External API unavailable"},
+	{Err: dragonball.ErrTimeout, Status: http.StatusGatewayTimeout, Code: "upstream_timeout", Title: "External API timed out"},
+	{Err: dragonball.ErrBadGateway, Status: http.StatusBadGateway, Code: "upstream_bad_gateway", Title: "External API returned an invalid response"},
+	{Err: dragonball.ErrNotFound, Status: http.StatusNotFound, Code: "not_found", Title: "Resource not found"},
+}
@@ -0,0 +1,25 @@
+package refresh
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	worker *Worker
+}
+
+func NewHandler(worker *Worker) *Handler {
+	return &Handler{worker: worker}
+}
+
+// RegisterAdminRoutes registers the maintenance routes in a protected group
+func (h *Handler) RegisterAdminRoutes(r gin.IRouter) {
+	r.GET("/refresh/status", h.Status) // GET /admin/refresh/status
+}
+
+// Status handles GET /admin/refresh/status, it tells what the worker did
+func (h *Handler) Status(c *gin.Context) {
+	c.JSON(http.StatusOK, h.worker.Status())
+}
@@ -0,0 +1,347 @@
+package refresh
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/cache"
+	"github.com/gclamigueiro/dragon-ball-api/internal/character"
+	"github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
+)
+
+// Default values of the worker options
+const (
+	DefaultConcurrency = 4
+	DefaultRate        = 2 // Requests per second
+	DefaultBatchSize   = 100
+)
+
+// Stats counts the outcome of the characters checked against the api
+type Stats struct {
+	Refreshed int `json:"refreshed"` // Checked against the api, including the changed and deleted ones
+	Changed   int `json:"changed"`   // The api had different data
+	Deleted   int `json:"deleted"`   // The api does not know the character anymore
+	Failed    int `json:"failed"`    // The api or the database failed, they are retried on the next run
+}
+
+func (s *Stats) add(other Stats) {
+	s.Refreshed += other.Refreshed
+	s.Changed += other.Changed
+	s.Deleted += other.Deleted
+	s.Failed += other.Failed
+}
+
+// Run is the result of a single pass of the worker
+type Run struct {
+	Stats
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"` // Nil while it runs
+}
+
+// Status is what the worker did since it started
+type Status struct {
+	Running   bool       `json:"running"`
+	Interval  string     `json:"interval"`
+	LastRun   *Run       `json:"last_run,omitempty"`
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+	Runs      int        `json:"runs"`
+	Totals    Stats      `json:"totals"`
+}
+
+// Worker periodically fetches again the characters saved in the database,
+// the oldest first, to notice the ones that changed or were removed upstream
+type Worker struct {
+	dgzClient  dragonball.Client
+	repository character.Repository
+
+	interval    time.Duration
+	concurrency int
+	limiter     *rate.Limiter
+	batchSize   int
+	cache       cache.Cache // Shared cache of the service, nil if disabled
+	logger      *slog.Logger
+
+	mu     sync.Mutex
+	status Status
+
+	// running serializes the runs, so their stats do not mix
+	running sync.Mutex
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Option configures the optional behaviour of the worker
+type Option func(*Worker)
+
+// WithConcurrency limits how many characters are fetched at the same time
+func WithConcurrency(n int) Option {
+	return func(w *Worker) {
+		w.concurrency = n
+	}
+}
+
+// WithRate limits the requests per second to the api, zero means no limit
+func WithRate(perSecond float64) Option {
+	return func(w *Worker) {
+		if perSecond <= 0 {
+			w.limiter = rate.NewLimiter(rate.Inf, 0)
+			return
+		}
+		w.limiter = rate.NewLimiter(rate.Limit(perSecond), 1)
+	}
+}
+
+// WithBatchSize limits how many characters are checked on each run
+func WithBatchSize(n int) Option {
+	return func(w *Worker) {
+		w.batchSize = n
+	}
+}
+
+// WithCache sets the cache shared by the replicas, the characters that
+// changed are removed from it so the service does not serve stale copies
+func WithCache(c cache.Cache) Option {
+	return func(w *Worker) {
+		w.cache = c
+	}
+}
+
+// WithLogger sets the logger of the runs, slog.Default() if not set
+func WithLogger(logger *slog.Logger) Option {
+	return func(w *Worker) {
+		w.logger = logger
+	}
+}
+
+// NewWorker checks the characters fetched longer than interval ago, once
+// per interval. The repository should be the one of the service, so its
+// cache sees the changes.
+func NewWorker(dgzClient dragonball.Client, repository character.Repository, interval time.Duration, opts ...Option) *Worker {
+	w := &Worker{
+		dgzClient:   dgzClient,
+		repository:  repository,
+		interval:    interval,
+		concurrency: DefaultConcurrency,
+		limiter:     rate.NewLimiter(DefaultRate, 1),
+		batchSize:   DefaultBatchSize,
+		logger:      slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.concurrency < 1 {
+		w.concurrency = 1
+	}
+	w.status.Interval = interval.String()
+	return w
+}
+
+// Start runs the worker in the background until Stop is called
+func (w *Worker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+
+	go func() {
+		defer close(w.done)
+		w.loop(ctx)
+	}()
+}
+
+// Stop cancels the current run and waits until the worker is done.
+// The characters being fetched are counted as failed.
+func (w *Worker) Stop() {
+	if w.cancel == nil {
+		return
+	}
+	w.cancel()
+	<-w.done
+}
+
+// Status returns a snapshot of what the worker did
+func (w *Worker) Status() Status {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	status := w.status
+	if status.LastRun != nil {
+		run := *status.LastRun
+		status.LastRun = &run
+	}
+	return status
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.setNextRun(time.Now().Add(w.interval))
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce checks a batch of the characters fetched longer than the interval
+// ago, and returns what it did
+func (w *Worker) RunOnce(ctx context.Context) Run {
+	w.running.Lock()
+	defer w.running.Unlock()
+
+	run := w.startRun()
+
+	characters, err := w.repository.FindStale(ctx, run.StartedAt.Add(-w.interval), w.batchSize)
+	if err != nil {
+		w.logger.ErrorContext(ctx, "refresh: failed to find the characters to refresh", "error", err)
+	}
+
+	queue := make(chan *character.Character)
+	var wg sync.WaitGroup
+	for range min(w.concurrency, len(characters)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for char := range queue {
+				w.record(w.refresh(ctx, char))
+			}
+		}()
+	}
+
+	for _, char := range characters {
+		if ctx.Err() != nil {
+			break
+		}
+		queue <- char
+	}
+	close(queue)
+	wg.Wait()
+
+	finished := w.finishRun()
+	w.logger.InfoContext(ctx, "refresh: run finished",
+		"refreshed", finished.Stats.Refreshed, "changed", finished.Stats.Changed,
+		"deleted", finished.Stats.Deleted, "failed", finished.Stats.Failed,
+		"duration_ms", finished.FinishedAt.Sub(finished.StartedAt).Milliseconds())
+	return finished
+}
+
+// refresh fetches the character again and saves what the api answered
+func (w *Worker) refresh(ctx context.Context, saved *character.Character) Stats {
+	if err := w.limiter.Wait(ctx); err != nil {
+		return Stats{Failed: 1}
+	}
+
+	detail, err := w.dgzClient.GetCharacterByID(ctx, saved.ID)
+	if err != nil {
+		w.logger.WarnContext(ctx, "refresh: failed to fetch the character", "character_id", saved.ID, "error", err)
+		return Stats{Failed: 1}
+	}
+
+	if detail == nil {
+		// Keep it, but flag it as removed so it is not checked until it expires again
+		stats := Stats{Refreshed: 1}
+		now := time.Now()
+		removed := *saved
+		removed.FetchedAt = now
+		removed.UpdatedAt = now // Only kept if deleted_at changes
+		if removed.DeletedAt == nil {
+			removed.DeletedAt = &now
+			stats.Changed, stats.Deleted = 1, 1
+		}
+		if err := w.repository.Save(ctx, &removed); err != nil {
+			w.logger.ErrorContext(ctx, "refresh: failed to save the character", "character_id", saved.ID, "error", err)
+			return Stats{Failed: 1}
+		}
+		if stats.Deleted == 1 {
+			w.invalidate(ctx, saved.Name)
+		}
+		return stats
+	}
+
+	fetched := character.FromAPIDetail(detail)
+	transformations := validTransformations(character.TransformationsFromAPIResponse(fetched.ID, detail.Transformations))
+	if err := w.repository.SaveTransformations(ctx, fetched, transformations); err != nil {
+		w.logger.ErrorContext(ctx, "refresh: failed to save the character", "character_id", saved.ID, "error", err)
+		return Stats{Failed: 1}
+	}
+
+	stats := Stats{Refreshed: 1}
+	if !saved.SameData(fetched) {
+		stats.Changed = 1
+		// The old name is removed too in case the character was renamed
+		w.invalidate(ctx, saved.Name, fetched.Name)
+	}
+	return stats
+}
+
+// invalidate removes the characters from the shared cache. It is best
+// effort, the errors are only logged and the copies expire on their own.
+func (w *Worker) invalidate(ctx context.Context, names ...string) {
+	if w.cache == nil {
+		return
+	}
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		keys = append(keys, character.NameCacheKey(name))
+	}
+	if err := w.cache.Delete(ctx, keys...); err != nil {
+		w.logger.WarnContext(ctx, "refresh: failed to invalidate the cache", "names", names, "error", err)
+	}
+}
+
+// validTransformations drops the transformations the database would reject,
+// like the catalog sync does
+func validTransformations(transformations []*character.Transformation) []*character.Transformation {
+	valid := transformations[:0]
+	for _, t := range transformations {
+		if t.IsValid() {
+			valid = append(valid, t)
+		}
+	}
+	return valid
+}
+
+func (w *Worker) startRun() Run {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	run := Run{StartedAt: time.Now()}
+	w.status.Running = true
+	w.status.LastRun = &run
+	w.status.NextRunAt = nil
+	return run
+}
+
+func (w *Worker) record(stats Stats) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.status.LastRun.add(stats)
+	w.status.Totals.add(stats)
+}
+
+func (w *Worker) finishRun() Run {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	w.status.Running = false
+	w.status.LastRun.FinishedAt = &now
+	w.status.Runs++
+	return *w.status.LastRun
+}
+
+func (w *Worker) setNextRun(at time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.status.NextRunAt = &at
+}
@@ -0,0 +1,251 @@
+package refresh_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/cache"
+	"github.com/gclamigueiro/dragon-ball-api/internal/character"
+	mock_character "github.com/gclamigueiro/dragon-ball-api/internal/character/mocks"
+	"github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
+	mock_dragonball "github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball/mocks"
+	"github.com/gclamigueiro/dragon-ball-api/internal/refresh"
+)
+
+func TestWorker_RunOnce(t *testing.T) {
+	mockClient := new(mock_dragonball.Client)
+	mockRepo := new(mock_character.Repository)
+	worker := refresh.NewWorker(mockClient, mockRepo, time.Hour, refresh.WithRate(0))
+	ctx := context.Background()
+
+	saved := []*character.Character{
+		{ID: 1, Name: "Goku", Race: "Saiyan"},
+		{ID: 2, Name: "Vegeta", Race: "Saiyan"},
+		{ID: 3, Name: "Cell"},
+		{ID: 4, Name: "Freezer"},
+	}
+	mockRepo.On("FindStale", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+		return time.Until(before) < -59*time.Minute
+	}), refresh.DefaultBatchSize).Return(saved, nil)
+
+	// Goku is the same, Vegeta changed, Cell was removed and Freezer fails
+	mockClient.On("GetCharacterByID", mock.Anything, 1).Return(&dragonball.CharacterDetail{
+		Character: dragonball.Character{ID: 1, Name: "Goku", Race: "Saiyan"},
+	}, nil)
+	mockClient.On("GetCharacterByID", mock.Anything, 2).Return(&dragonball.CharacterDetail{
+		Character: dragonball.Character{ID: 2, Name: "Vegeta", Race: "Saiyan", Affiliation: "Z Fighter"},
+	}, nil)
+	mockClient.On("GetCharacterByID", mock.Anything, 3).Return(nil, nil)
+	mockClient.On("GetCharacterByID", mock.Anything, 4).Return(nil, dragonball.ErrUnavailable)
+
+	mockRepo.On("SaveTransformations", mock.Anything, mock.Anything, mock.Anything).Return(nil).Twice()
+	mockRepo.On("Save", mock.Anything, mock.MatchedBy(func(c *character.Character) bool {
+		return c.ID == 3 && c.DeletedAt != nil
+	})).Return(nil)
+
+	run := worker.RunOnce(ctx)
+	assert.Equal(t, refresh.Stats{Refreshed: 3, Changed: 2, Deleted: 1, Failed: 1}, run.Stats)
+	assert.NotNil(t, run.FinishedAt)
+
+	status := worker.Status()
+	assert.False(t, status.Running)
+	assert.Equal(t, 1, status.Runs)
+	assert.Equal(t, run.Stats, status.Totals)
+	mockClient.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWorker_RunOnce_InvalidatesCache(t *testing.T) {
+	mockClient := new(mock_dragonball.Client)
+	mockRepo := new(mock_character.Repository)
+	shared := cache.NewMemory(10)
+	worker := refresh.NewWorker(mockClient, mockRepo, time.Hour, refresh.WithRate(0), refresh.WithCache(shared))
+	ctx := context.Background()
+
+	for _, name := range []string{"Goku", "Kakarotto", "Vegeta", "Cell"} {
+		assert.NoError(t, shared.Set(ctx, character.NameCacheKey(name), []byte("{}"), time.Hour))
+	}
+
+	// Goku is the same, Kakarotto was renamed to Vegeta and Cell was removed
+	mockRepo.On("FindStale", mock.Anything, mock.Anything, mock.Anything).Return([]*character.Character{
+		{ID: 1, Name: "Goku"},
+		{ID: 2, Name: "Kakarotto"},
+		{ID: 3, Name: "Cell"},
+	}, nil)
+	mockClient.On("GetCharacterByID", mock.Anything, 1).Return(&dragonball.CharacterDetail{
+		Character: dragonball.Character{ID: 1, Name: "Goku"},
+	}, nil)
+	mockClient.On("GetCharacterByID", mock.Anything, 2).Return(&dragonball.CharacterDetail{
+		Character: dragonball.Character{ID: 2, Name: "Vegeta"},
+	}, nil)
+	mockClient.On("GetCharacterByID", mock.Anything, 3).Return(nil, nil)
+	mockRepo.On("SaveTransformations", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("Save", mock.Anything, mock.Anything).Return(nil)
+
+	run := worker.RunOnce(ctx)
+	assert.Equal(t, refresh.Stats{Refreshed: 3, Changed: 2, Deleted: 1}, run.Stats)
+
+	for name, cached := range map[string]bool{"Goku": true, "Kakarotto": false, "Vegeta": false, "Cell": false} {
+		_, ok, err := shared.Get(ctx, character.NameCacheKey(name))
+		assert.NoError(t, err)
+		assert.Equal(t, cached, ok, name)
+	}
+	mockClient.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWorker_RunOnce_KeepsCacheOnFailure(t *testing.T) {
+	mockClient := new(mock_dragonball.Client)
+	mockRepo := new(mock_character.Repository)
+	shared := cache.NewMemory(10)
+	worker := refresh.NewWorker(mockClient, mockRepo, time.Hour, refresh.WithRate(0), refresh.WithCache(shared))
+	ctx := context.Background()
+
+	assert.NoError(t, shared.Set(ctx, character.NameCacheKey("Goku"), []byte("{}"), time.Hour))
+
+	// The cached copy is still the saved one if the change cannot be saved
+	mockRepo.On("FindStale", mock.Anything, mock.Anything, mock.Anything).
+		Return([]*character.Character{{ID: 1, Name: "Goku"}}, nil)
+	mockClient.On("GetCharacterByID", mock.Anything, 1).Return(&dragonball.CharacterDetail{
+		Character: dragonball.Character{ID: 1, Name: "Goku", Race: "Saiyan"},
+	}, nil)
+	mockRepo.On("SaveTransformations", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+
+	run := worker.RunOnce(ctx)
+	assert.Equal(t, refresh.Stats{Failed: 1}, run.Stats)
+
+	_, ok, _ := shared.Get(ctx, character.NameCacheKey("Goku"))
+	assert.True(t, ok)
+	mockClient.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWorker_RunOnce_SkipsInvalidTransformations(t *testing.T) {
+	mockClient := new(mock_dragonball.Client)
+	mockRepo := new(mock_character.Repository)
+	worker := refresh.NewWorker(mockClient, mockRepo, time.Hour, refresh.WithRate(0))
+
+	mockRepo.On("FindStale", mock.Anything, mock.Anything, mock.Anything).
+		Return([]*character.Character{{ID: 1, Name: "Goku"}}, nil)
+	mockClient.On("GetCharacterByID", mock.Anything, 1).Return(&dragonball.CharacterDetail{
+		Character: dragonball.Character{ID: 1, Name: "Goku"},
+		Transformations: []*dragonball.Transformation{
+			{ID: 1, Name: "Goku SSJ", Ki: "3 Billion"},
+			{ID: 2, Name: ""},
+			{Name: "Goku SSJ2"},
+		},
+	}, nil)
+	mockRepo.On("SaveTransformations", mock.Anything, mock.Anything, mock.MatchedBy(func(transformations []*character.Transformation) bool {
+		return len(transformations) == 1 && transformations[0].ID == 1
+	})).Return(nil)
+
+	run := worker.RunOnce(context.Background())
+	assert.Equal(t, refresh.Stats{Refreshed: 1}, run.Stats)
+	mockClient.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWorker_RunOnce_LimitsConcurrency(t *testing.T) {
+	mockClient := new(mock_dragonball.Client)
+	mockRepo := new(mock_character.Repository)
+	worker := refresh.NewWorker(mockClient, mockRepo, time.Hour, refresh.WithRate(0), refresh.WithConcurrency(2))
+
+	saved := make([]*character.Character, 6)
+	for i := range saved {
+		saved[i] = &character.Character{ID: i + 1, Name: "Goku"}
+	}
+	mockRepo.On("FindStale", mock.Anything, mock.Anything, mock.Anything).Return(saved, nil)
+
+	var current, peak atomic.Int32
+	mockClient.On("GetCharacterByID", mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		n := current.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		current.Add(-1)
+	}).Return(nil, errors.New("boom"))
+
+	run := worker.RunOnce(context.Background())
+	assert.Equal(t, 6, run.Failed)
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+}
+
+func TestWorker_RunOnce_RespectsRate(t *testing.T) {
+	mockClient := new(mock_dragonball.Client)
+	mockRepo := new(mock_character.Repository)
+	worker := refresh.NewWorker(mockClient, mockRepo, time.Hour, refresh.WithRate(50), refresh.WithConcurrency(4))
+
+	saved := make([]*character.Character, 6)
+	for i := range saved {
+		saved[i] = &character.Character{ID: i + 1, Name: "Goku"}
+	}
+	mockRepo.On("FindStale", mock.Anything, mock.Anything, mock.Anything).Return(saved, nil)
+	mockClient.On("GetCharacterByID", mock.Anything, mock.Anything).Return(nil, errors.New("boom"))
+
+	// 6 requests at 50 per second take at least 100ms after the first one
+	start := time.Now()
+	worker.RunOnce(context.Background())
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestWorker_StartStop(t *testing.T) {
+	mockClient := new(mock_dragonball.Client)
+	mockRepo := new(mock_character.Repository)
+	worker := refresh.NewWorker(mockClient, mockRepo, 10*time.Millisecond)
+
+	ran := make(chan struct{}, 1)
+	mockRepo.On("FindStale", mock.Anything, mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		select {
+		case ran <- struct{}{}:
+		default:
+		}
+	}).Return(nil, nil)
+
+	worker.Start()
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("the worker did not run")
+	}
+	worker.Stop()
+
+	calls := len(mockRepo.Calls)
+	time.Sleep(30 * time.Millisecond)
+	assert.Len(t, mockRepo.Calls, calls, "the worker kept running after Stop")
+}
+
+func TestHandler_Status(t *testing.T) {
+	mockClient := new(mock_dragonball.Client)
+	mockRepo := new(mock_character.Repository)
+	worker := refresh.NewWorker(mockClient, mockRepo, time.Hour)
+	mockRepo.On("FindStale", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	worker.RunOnce(context.Background())
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	refresh.NewHandler(worker).RegisterAdminRoutes(r.Group("/admin"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/refresh/status", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "1h0m0s", resp["interval"])
+	assert.Equal(t, float64(1), resp["runs"])
+	assert.Contains(t, resp["last_run"], "refreshed")
+}
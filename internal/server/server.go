@@ -0,0 +1,99 @@
+// Package server runs the http server with timeouts and a graceful shutdown
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Default values of the server options
+const (
+	DefaultReadTimeout       = 10 * time.Second
+	DefaultReadHeaderTimeout = 5 * time.Second
+	DefaultWriteTimeout      = 30 * time.Second
+	DefaultIdleTimeout       = 60 * time.Second
+	DefaultMaxHeaderBytes    = 64 << 10
+	DefaultShutdownTimeout   = 15 * time.Second
+)
+
+// Config holds the limits of the server
+type Config struct {
+	Addr              string
+	ReadTimeout       time.Duration // Max duration to read the whole request
+	ReadHeaderTimeout time.Duration // Max duration to read the headers
+	WriteTimeout      time.Duration // Max duration from the end of the headers to the end of the response
+	IdleTimeout       time.Duration // How long a keep-alive connection waits for the next request
+	MaxHeaderBytes    int           // Max size of the request headers
+}
+
+// New creates the server, zero values take the defaults
+func New(handler http.Handler, cfg Config) *http.Server {
+	return &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadTimeout:       orDefault(cfg.ReadTimeout, DefaultReadTimeout),
+		ReadHeaderTimeout: orDefault(cfg.ReadHeaderTimeout, DefaultReadHeaderTimeout),
+		WriteTimeout:      orDefault(cfg.WriteTimeout, DefaultWriteTimeout),
+		IdleTimeout:       orDefault(cfg.IdleTimeout, DefaultIdleTimeout),
+		MaxHeaderBytes:    orDefault(cfg.MaxHeaderBytes, DefaultMaxHeaderBytes),
+	}
+}
+
+// Run listens on the address of the server and serves until ctx is done, see Serve
+func Run(ctx context.Context, srv *http.Server, drainTimeout time.Duration, onShutdown ...func() error) error {
+	listener, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", srv.Addr, err)
+	}
+	return Serve(ctx, srv, listener, drainTimeout, onShutdown...)
+}
+
+// Serve serves until ctx is done, e.g. on SIGTERM. Then it stops accepting
+// connections and waits up to drainTimeout for the in-flight requests.
+// The onShutdown funcs run after, in order, even if the drain timed out,
+// so the workers and the database pool are closed once nothing uses them.
+func Serve(ctx context.Context, srv *http.Server, listener net.Listener, drainTimeout time.Duration, onShutdown ...func() error) error {
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.Serve(listener)
+	}()
+
+	var errs []error
+	select {
+	case err := <-served:
+		// The server failed on its own, there is nothing to drain
+		errs = append(errs, err)
+	case <-ctx.Done():
+		slog.Info("shutting down, waiting for the in-flight requests", "drain_timeout", drainTimeout.String())
+
+		drainCtx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+		defer cancel()
+		if err := srv.Shutdown(drainCtx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to drain the requests: %w", err))
+			_ = srv.Close()
+		}
+		if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, shutdown := range onShutdown {
+		if err := shutdown(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func orDefault[T comparable](value, fallback T) T {
+	var zero T
+	if value == zero {
+		return fallback
+	}
+	return value
+}
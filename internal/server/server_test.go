@@ -0,0 +1,124 @@
+package server_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/server"
+)
+
+// slowHandler answers once release is closed, telling when a request arrives
+func slowHandler(started chan<- struct{}, release <-chan struct{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		_, _ = w.Write([]byte("done"))
+	})
+}
+
+func serve(t *testing.T, ctx context.Context, handler http.Handler, drain time.Duration, onShutdown ...func() error) (string, <-chan error) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := server.New(handler, server.Config{})
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- server.Serve(ctx, srv, listener, drain, onShutdown...)
+	}()
+	return "http://" + listener.Addr().String(), stopped
+}
+
+func TestServe_CompletesInFlightRequests(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	started, release := make(chan struct{}), make(chan struct{})
+
+	var order []string
+	url, stopped := serve(t, ctx, slowHandler(started, release), 5*time.Second,
+		func() error { order = append(order, "worker"); return nil },
+		func() error { order = append(order, "db"); return nil },
+	)
+
+	type result struct {
+		body string
+		err  error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			responses <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		responses <- result{string(body), err}
+	}()
+	<-started
+
+	// Shut down while the request is in flight
+	cancel()
+	select {
+	case <-stopped:
+		t.Fatal("the server stopped before the in-flight request completed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// New connections are refused while draining
+	_, err := http.Get(url)
+	assert.Error(t, err)
+
+	close(release)
+	res := <-responses
+	assert.NoError(t, res.err)
+	assert.Equal(t, "done", res.body)
+
+	assert.NoError(t, <-stopped)
+	assert.Equal(t, []string{"worker", "db"}, order)
+}
+
+func TestServe_DrainDeadline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+
+	closed := false
+	url, stopped := serve(t, ctx, slowHandler(started, release), 50*time.Millisecond,
+		func() error { closed = true; return nil },
+	)
+
+	go func() {
+		resp, err := http.Get(url)
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+	cancel()
+
+	select {
+	case err := <-stopped:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.True(t, closed, "the shutdown funcs must run even if the drain timed out")
+	case <-time.After(time.Second):
+		t.Fatal("the server did not stop after the drain deadline")
+	}
+}
+
+func TestNew_Defaults(t *testing.T) {
+	srv := server.New(http.NotFoundHandler(), server.Config{Addr: ":8080", WriteTimeout: time.Minute})
+
+	assert.Equal(t, ":8080", srv.Addr)
+	assert.Equal(t, time.Minute, srv.WriteTimeout)
+	assert.Equal(t, server.DefaultReadTimeout, srv.ReadTimeout)
+	assert.Equal(t, server.DefaultReadHeaderTimeout, srv.ReadHeaderTimeout)
+	assert.Equal(t, server.DefaultIdleTimeout, srv.IdleTimeout)
+	assert.Equal(t, server.DefaultMaxHeaderBytes, srv.MaxHeaderBytes)
+}
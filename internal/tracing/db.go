@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// spanKey keeps the span of the query in the statement
+const spanKey = "tracing:span"
+
+// tracerName identifies the spans created by this package
+const tracerName = "github.com/gclamigueiro/dragon-ball-api/internal/tracing"
+
+// InstrumentDB creates a span for every query made through GORM, as a child
+// of the span in the context given to WithContext
+func InstrumentDB(db *gorm.DB) error {
+	callbacks := db.Callback()
+	hooks := []struct {
+		operation string
+		before    error
+		after     error
+	}{
+		{"create",
+			callbacks.Create().Before("gorm:create").Register("tracing:before_create", startSpan("create")),
+			callbacks.Create().After("gorm:create").Register("tracing:after_create", endSpan)},
+		{"query",
+			callbacks.Query().Before("gorm:query").Register("tracing:before_query", startSpan("query")),
+			callbacks.Query().After("gorm:query").Register("tracing:after_query", endSpan)},
+		{"update",
+			callbacks.Update().Before("gorm:update").Register("tracing:before_update", startSpan("update")),
+			callbacks.Update().After("gorm:update").Register("tracing:after_update", endSpan)},
+		{"delete",
+			callbacks.Delete().Before("gorm:delete").Register("tracing:before_delete", startSpan("delete")),
+			callbacks.Delete().After("gorm:delete").Register("tracing:after_delete", endSpan)},
+		{"row",
+			callbacks.Row().Before("gorm:row").Register("tracing:before_row", startSpan("row")),
+			callbacks.Row().After("gorm:row").Register("tracing:after_row", endSpan)},
+		{"raw",
+			callbacks.Raw().Before("gorm:raw").Register("tracing:before_raw", startSpan("raw")),
+			callbacks.Raw().After("gorm:raw").Register("tracing:after_raw", endSpan)},
+	}
+	for _, hook := range hooks {
+		if err := errors.Join(hook.before, hook.after); err != nil {
+			return fmt.Errorf("failed to register the %s callbacks: %w", hook.operation, err)
+		}
+	}
+	return nil
+}
+
+func startSpan(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		_, span := otel.Tracer(tracerName).Start(tx.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", "postgresql"),
+				attribute.String("db.operation.name", operation),
+			),
+		)
+		tx.InstanceSet(spanKey, span)
+	}
+}
+
+// endSpan records the query, with its placeholders but never its values.
+// A lookup that finds nothing is not an error.
+func endSpan(tx *gorm.DB) {
+	value, ok := tx.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.collection.name", tx.Statement.Table),
+		attribute.String("db.query.text", tx.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+	)
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(tx.Error)
+		span.SetStatus(codes.Error, tx.Error.Error())
+	}
+}
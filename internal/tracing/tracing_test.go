@@ -0,0 +1,103 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/character"
+	"github.com/gclamigueiro/dragon-ball-api/internal/client/dragonball"
+	"github.com/gclamigueiro/dragon-ball-api/internal/tracing"
+	"github.com/gclamigueiro/dragon-ball-api/internal/tracing/tracingtest"
+)
+
+func TestMiddleware(t *testing.T) {
+	spans := tracingtest.Install(t)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(tracing.Middleware("test"))
+	var handlerSpan trace.SpanContext
+	r.GET("/characters/:name", func(c *gin.Context) {
+		handlerSpan = trace.SpanContextFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+	r.GET("/readyz", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	// The trace of the caller is continued
+	req := httptest.NewRequest(http.MethodGet, "/characters/goku", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	recorded := spans.GetSpans()
+	require.Len(t, recorded, 1, "the probes should not be traced")
+	assert.Equal(t, "GET /characters/:name", recorded[0].Name)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", recorded[0].SpanContext.TraceID().String())
+	assert.Equal(t, recorded[0].SpanContext.SpanID(), handlerSpan.SpanID())
+}
+
+func TestNewTransport(t *testing.T) {
+	spans := tracingtest.Install(t)
+
+	var traceparent string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		_, _ = w.Write([]byte(`{"id": 1, "name": "Goku"}`))
+	}))
+	defer upstream.Close()
+
+	client := dragonball.NewClient(upstream.URL, dragonball.WithTransport(tracing.NewTransport(http.DefaultTransport)))
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	_, err := client.GetCharacterByID(ctx, 1)
+	parent.End()
+	require.NoError(t, err)
+
+	recorded := tracingtest.Find(spans, "dragonball GET")
+	require.Len(t, recorded, 1)
+	assert.Equal(t, trace.SpanKindClient, recorded[0].SpanKind)
+	assert.Equal(t, parent.SpanContext().SpanID(), recorded[0].Parent.SpanID())
+	assert.Contains(t, traceparent, recorded[0].SpanContext.TraceID().String())
+	assert.Contains(t, traceparent, recorded[0].SpanContext.SpanID().String())
+}
+
+func TestInstrumentDB(t *testing.T) {
+	spans := tracingtest.Install(t)
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, tracing.InstrumentDB(db))
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	repository := character.NewStorage(db)
+	_, _ = repository.FindByName(ctx, "Goku", character.MatchExact)
+	parent.End()
+
+	recorded := tracingtest.Find(spans, "gorm.query")
+	require.Len(t, recorded, 1)
+	assert.Equal(t, parent.SpanContext().SpanID(), recorded[0].Parent.SpanID())
+	assert.Contains(t, recorded[0].Attributes, attribute.String("db.collection.name", "characters"))
+
+	// Only the placeholders are recorded, never the values
+	var statement string
+	for _, attr := range recorded[0].Attributes {
+		if attr.Key == "db.query.text" {
+			statement = attr.Value.AsString()
+		}
+	}
+	assert.Contains(t, statement, "LOWER(name) LIKE LOWER($1)")
+	assert.NotContains(t, statement, "Goku")
+}
@@ -0,0 +1,42 @@
+// Package tracingtest records the spans in memory, to assert them in tests
+package tracingtest
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/gclamigueiro/dragon-ball-api/internal/tracing"
+)
+
+// Install makes the global tracer provider record every span in the returned
+// exporter until the test ends. Tests using it must not run in parallel.
+func Install(t testing.TB) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewProvider("test", sdktrace.WithSyncer(exporter))
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(tracing.Propagator)
+	t.Cleanup(func() {
+		_ = provider.Shutdown(t.Context())
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return exporter
+}
+
+// Find returns the spans with the name
+func Find(exporter *tracetest.InMemoryExporter, name string) tracetest.SpanStubs {
+	var spans tracetest.SpanStubs
+	for _, span := range exporter.GetSpans() {
+		if span.Name == name {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}